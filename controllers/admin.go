@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// indexInfo is the public description of a collection index. Keys are listed in
+// index order as "field:direction", e.g. "email:1".
+type indexInfo struct {
+	Name   string   `json:"name"`
+	Keys   []string `json:"keys"`
+	Unique bool     `json:"unique,omitempty"`
+}
+
+// Reindex - HTTP handler to (re)create the application indexes on demand
+//
+// Safe to call repeatedly: existing indexes with the same definition are left
+// untouched. Responds with the indexes present afterwards.
+func Reindex(w http.ResponseWriter, r *http.Request) {
+	if err := ensureUniqueKeyIndex(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create indexes: %v", err))
+		return
+	}
+	if err := ensureIndexes(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create indexes: %v", err))
+		return
+	}
+	connInfo.IndexesCreated = true
+
+	indexes, err := listIndexes(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list indexes: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Indexes ensured successfully",
+		"indexes": indexes,
+	})
+}
+
+// listIndexes returns every index currently defined on the employee collection.
+func listIndexes(ctx context.Context) ([]indexInfo, error) {
+	cur, err := collection.Indexes().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing indexes: %w", err)
+	}
+	defer closeCursor(ctx, cur)
+
+	var specs []struct {
+		Name   string `bson:"name"`
+		Key    bson.D `bson:"key"`
+		Unique bool   `bson:"unique"`
+	}
+	if err := cur.All(ctx, &specs); err != nil {
+		return nil, fmt.Errorf("error decoding indexes: %w", err)
+	}
+
+	indexes := make([]indexInfo, 0, len(specs))
+	for _, spec := range specs {
+		info := indexInfo{Name: spec.Name, Unique: spec.Unique}
+		for _, key := range spec.Key {
+			info.Keys = append(info.Keys, fmt.Sprintf("%s:%v", key.Key, key.Value))
+		}
+		indexes = append(indexes, info)
+	}
+	return indexes, nil
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// fieldAliasProfiles parses FIELD_ALIASES, named sets of JSON key renames
+// such as "legacy=name:full_name,email:emailAddress;crm=department:team".
+// Malformed entries are ignored with a warning. The variable is read once.
+var fieldAliasProfiles = sync.OnceValue(func() map[string]map[string]string {
+	profiles := map[string]map[string]string{}
+	for _, entry := range strings.Split(os.Getenv("FIELD_ALIASES"), ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, pairs, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			log.Printf("Warning: ignoring FIELD_ALIASES entry %q: expected profile=field:alias,...", entry)
+			continue
+		}
+		aliases := map[string]string{}
+		for _, pair := range strings.Split(pairs, ",") {
+			field, alias, ok := strings.Cut(pair, ":")
+			field, alias = strings.TrimSpace(field), strings.TrimSpace(alias)
+			if !ok || field == "" || alias == "" {
+				log.Printf("Warning: ignoring FIELD_ALIASES pair %q of profile %q: expected field:alias", pair, name)
+				continue
+			}
+			aliases[field] = alias
+		}
+		profiles[name] = aliases
+	}
+	return profiles
+})
+
+// FieldAliases renames the keys of every JSON response of a request to those
+// of a FIELD_ALIASES profile, chosen with the X-Field-Aliases header or, for
+// clients that cannot set headers, ?aliases=. Keys are renamed wherever they
+// appear in the body, at any depth, and keep their order. XML and streamed
+// responses are left as they are. An unknown profile is answered with 400.
+func FieldAliases(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.Header.Get("X-Field-Aliases")
+		if name == "" {
+			name = r.URL.Query().Get("aliases")
+		}
+		if name = strings.TrimSpace(name); name == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		aliases, ok := fieldAliasProfiles()[name]
+		if !ok {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Unknown field alias profile %q", name))
+			return
+		}
+		w.Header().Add("Vary", "X-Field-Aliases")
+		next.ServeHTTP(&aliasWriter{ResponseWriter: w, aliases: aliases}, r)
+	})
+}
+
+// aliasWriter carries the key renames of a response to marshalJSON.
+type aliasWriter struct {
+	http.ResponseWriter
+	aliases map[string]string
+}
+
+func (w *aliasWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func (w *aliasWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *aliasWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// responseAliases returns the key renames of w, or any writer it wraps, if any.
+func responseAliases(w http.ResponseWriter) map[string]string {
+	for {
+		if aliased, ok := w.(*aliasWriter); ok {
+			return aliased.aliases
+		}
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return nil
+		}
+		w = unwrapper.Unwrap()
+	}
+}
+
+// renameJSONKeys rewrites the object keys of the encoded JSON body that have
+// an alias, leaving everything else, key order included, unchanged.
+func renameJSONKeys(body []byte, aliases map[string]string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var out bytes.Buffer
+	if err := copyRenamed(dec, &out, aliases); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// copyRenamed copies the next JSON value of dec to out, renaming keys.
+func copyRenamed(dec *json.Decoder, out *bytes.Buffer, aliases map[string]string) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		scalar, err := json.Marshal(token)
+		if err != nil {
+			return err
+		}
+		out.Write(scalar)
+		return nil
+	}
+
+	out.WriteRune(rune(delim))
+	for i := 0; dec.More(); i++ {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		if delim == '{' {
+			token, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := token.(string)
+			if alias, ok := aliases[key]; ok {
+				key = alias
+			}
+			encoded, err := json.Marshal(key)
+			if err != nil {
+				return err
+			}
+			out.Write(encoded)
+			out.WriteByte(':')
+		}
+		if err := copyRenamed(dec, out, aliases); err != nil {
+			return err
+		}
+	}
+	// The closing delimiter.
+	end, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	out.WriteRune(rune(end.(json.Delim)))
+	return nil
+}
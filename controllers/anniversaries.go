@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	defaultAnniversaryWindow = 7
+	maxAnniversaryWindow     = 365
+)
+
+// anniversary is an upcoming work anniversary of an employee.
+type anniversary struct {
+	Employee models.Employee `json:"employee"`
+	Date     string          `json:"date"`
+	Years    int             `json:"years"`
+}
+
+// GetAnniversaries - HTTP handler to list the work anniversaries of the next N days
+//
+// ?window= is the number of days ahead, today included, and defaults to 7
+// (capped at 365). Anniversaries are matched on the month and day of
+// joinedAt, in UTC, whatever the year; employees who joined on February 29
+// celebrate on February 28 in other years. Each result is {"employee",
+// "date", "years"}, ordered by date; employees yet to reach their first year
+// are left out. The list filters (department, status, ...) apply.
+func GetAnniversaries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	window := defaultAnniversaryWindow
+	if raw := query.Get("window"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxAnniversaryWindow {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Query parameter 'window' must be between 1 and %d", maxAnniversaryWindow))
+			return
+		}
+		window = n
+	}
+
+	filter, err := buildEmployeeFilter(query, canViewPII(r))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	filter["joinedAt"] = bson.M{"$lt": today}
+	filter["$expr"] = bson.M{"$in": bson.A{
+		bson.M{"$add": bson.A{
+			bson.M{"$multiply": bson.A{bson.M{"$month": "$joinedAt"}, 100}},
+			bson.M{"$dayOfMonth": "$joinedAt"},
+		}},
+		anniversaryDays(today, window),
+	}}
+
+	employees, truncated, err := getAllEmployees(r.Context(), filter, nil)
+	if err != nil {
+		writeServerError(w, "Failed to retrieve employees", err)
+		return
+	}
+	if truncated {
+		w.Header().Set("X-Result-Truncated", "true")
+	}
+	maskPII := !canViewPII(r)
+
+	anniversaries := []anniversary{}
+	for _, employee := range employees {
+		date := nextAnniversary(*employee.JoinedAt, today)
+		years := date.Year() - employee.JoinedAt.Year()
+		if years < 1 {
+			continue
+		}
+		if maskPII {
+			employee = maskEmployee(employee)
+		}
+		anniversaries = append(anniversaries, anniversary{Employee: employee, Date: date.Format("2006-01-02"), Years: years})
+	}
+	sort.SliceStable(anniversaries, func(i, j int) bool {
+		if anniversaries[i].Date != anniversaries[j].Date {
+			return anniversaries[i].Date < anniversaries[j].Date
+		}
+		return anniversaries[i].Employee.Name < anniversaries[j].Employee.Name
+	})
+
+	writeJSON(w, http.StatusOK, anniversaries)
+}
+
+// anniversaryDays returns the days of the window days starting at today as
+// month*100 + day, e.g. 1231 for December 31, the form compared against
+// joinedAt. A February 28 outside a leap year also stands for February 29.
+func anniversaryDays(today time.Time, window int) bson.A {
+	days := bson.A{}
+	for i := 0; i < window; i++ {
+		day := today.AddDate(0, 0, i)
+		days = append(days, int(day.Month())*100+day.Day())
+		if day.Month() == time.February && day.Day() == 28 && !isLeapYear(day.Year()) {
+			days = append(days, 229)
+		}
+	}
+	return days
+}
+
+// nextAnniversary returns the first anniversary of joinedAt on or after today,
+// moving February 29 to February 28 in years without it.
+func nextAnniversary(joinedAt, today time.Time) time.Time {
+	for year := today.Year(); ; year++ {
+		month, day := joinedAt.Month(), joinedAt.Day()
+		if month == time.February && day == 29 && !isLeapYear(year) {
+			day = 28
+		}
+		date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		if !date.Before(today) {
+			return date
+		}
+	}
+}
+
+// isLeapYear reports whether year has a February 29.
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
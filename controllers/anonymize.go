@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// errAlreadyAnonymized is returned when anonymizing a record a second time.
+var errAlreadyAnonymized = errors.New("employee has already been anonymized")
+
+// redactedValue replaces personal data in audit history.
+const redactedValue = "[redacted]"
+
+// anonymizedFields hold personal data. They are overwritten with placeholders
+// or removed on anonymization, and their values are redacted from the audit log.
+var anonymizedFields = []string{"name", "email", "phone", "address"}
+
+// AnonymizeEmployee - HTTP handler to irreversibly scrub an employee's personal data
+//
+// The record keeps its _id, department, manager and employment fields so that
+// references and aggregates stay intact. Soft-deleted records can be
+// anonymized too.
+func AnonymizeEmployee(w http.ResponseWriter, r *http.Request) {
+	employee, err := anonymizeEmployee(r.Context(), employeeIDFromRequest(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, errEmployeeNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, errAlreadyAnonymized):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeServerError(w, "Failed to anonymize employee", err)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Employee anonymized successfully",
+		"data":    employee,
+	})
+}
+
+// anonymizeEmployee replaces the personal data of an employee with
+// placeholders and redacts the previous values from its audit history, inside
+// one transaction so no copy of the data survives a partial failure, and
+// records the anonymization as withTransaction does. The email placeholder is derived from the _id to
+// keep the unique index satisfied; see anonymizedName for the name.
+func anonymizeEmployee(ctx context.Context, id bson.ObjectID) (models.Employee, error) {
+	var employee models.Employee
+
+	session, err := startSession()
+	if err != nil {
+		return employee, err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = withTransaction(ctx, session, func(ctx context.Context) (interface{}, error) {
+		before, err := findDocument(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := before["anonymizedAt"]; ok {
+			return nil, fmt.Errorf("%w: %s", errAlreadyAnonymized, id.Hex())
+		}
+
+		now := time.Now().UTC()
+		_, err = collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+			"$set": bson.M{
+				"name":         anonymizedName(id),
+				"nameSearch":   foldSearchText(anonymizedName(id)),
+				"email":        fmt.Sprintf("anonymized-%s@anonymized.invalid", id.Hex()),
+				"phone":        "REDACTED",
+				"anonymizedAt": now,
+				"updatedAt":    now,
+			},
+			"$unset": bson.M{"address": "", "phoneDigits": ""},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error anonymizing employee: %w", err)
+		}
+
+		_, err = auditCollection.UpdateMany(ctx, bson.M{"employeeId": id},
+			bson.M{"$set": bson.M{
+				"changes.$[c].oldValue": redactedValue,
+				"changes.$[c].newValue": redactedValue,
+			}},
+			options.UpdateMany().SetArrayFilters([]interface{}{
+				bson.M{"c.field": bson.M{"$in": anonymizedFields}},
+			}),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error redacting audit history: %w", err)
+		}
+
+		after, err := findDocument(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		redacted := bson.M{}
+		for key, value := range before {
+			redacted[key] = value
+		}
+		for _, field := range anonymizedFields {
+			if _, ok := redacted[field]; ok {
+				redacted[field] = redactedValue
+			}
+		}
+		if err := recordAudit(ctx, auditAnonymize, id, redacted, after); err != nil {
+			return nil, err
+		}
+
+		return nil, decodeDocument(after, &employee)
+	})
+	detailCache().invalidate(id.Hex())
+	if err != nil {
+		return employee, err
+	}
+
+	fmt.Printf("Anonymized employee %s\n", id.Hex())
+	return employee, nil
+}
+
+// anonymizedName is the placeholder name of an anonymized employee. It carries
+// the _id when UNIQUE_KEY includes the name, so anonymized employees of one
+// department do not collide.
+func anonymizedName(id bson.ObjectID) string {
+	if contains(uniqueKey, "name") {
+		return "Anonymized Employee " + id.Hex()
+	}
+	return "Anonymized Employee"
+}
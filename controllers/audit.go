@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Audit actions.
+const (
+	auditCreate    = "create"
+	auditUpdate    = "update"
+	auditStatus    = "status"
+	auditDelete    = "delete"
+	auditMerge     = "merge"
+	auditAnonymize = "anonymize"
+)
+
+// auditCollection stores one auditEntry per employee mutation. It is named by
+// MONGODB_AUDIT_COLLECTION_NAME, defaulting to "<collection>_audit".
+var auditCollection *mongo.Collection
+
+// auditChange is a single field-level difference.
+type auditChange struct {
+	Field    string      `json:"field" bson:"field"`
+	OldValue interface{} `json:"oldValue" bson:"oldValue"`
+	NewValue interface{} `json:"newValue" bson:"newValue"`
+}
+
+// auditEntry records who changed which fields of an employee, and when.
+type auditEntry struct {
+	ID         bson.ObjectID `json:"id" bson:"_id,omitempty"`
+	EmployeeID bson.ObjectID `json:"employeeId" bson:"employeeId"`
+	Action     string        `json:"action" bson:"action"`
+	Actor      string        `json:"actor" bson:"actor"`
+	Timestamp  time.Time     `json:"timestamp" bson:"timestamp"`
+	Changes    []auditChange `json:"changes" bson:"changes"`
+}
+
+// auditIgnoredFields are bookkeeping fields left out of diffs.
+var auditIgnoredFields = map[string]bool{
+	"_id":         true,
+	"updatedAt":   true,
+	"phoneDigits": true,
+	"nameSearch":  true,
+}
+
+// auditIndexes support reading the history of one employee in order.
+var auditIndexes = []mongo.IndexModel{
+	{
+		Keys:    bson.D{{Key: "employeeId", Value: 1}, {Key: "_id", Value: 1}},
+		Options: options.Index().SetName("employeeId_1__id_1"),
+	},
+}
+
+// openAuditCollection configures auditCollection on db. Nested documents are
+// decoded as maps so that stored values render as plain JSON objects.
+func openAuditCollection(db *mongo.Database, name string) {
+	auditCollection = db.Collection(name, options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true}))
+}
+
+// actorFromContext names the authenticated caller for audit purposes.
+func actorFromContext(ctx context.Context) string {
+	if principal, ok := ctx.Value(principalKey{}).(Principal); ok {
+		return principal.Name
+	}
+	return "anonymous"
+}
+
+// newAuditEntry builds an entry describing the change from before to after.
+// Either document may be nil, for creates and deletes respectively.
+func newAuditEntry(ctx context.Context, action string, employeeID bson.ObjectID, before, after bson.M) auditEntry {
+	return auditEntry{
+		EmployeeID: employeeID,
+		Action:     action,
+		Actor:      actorFromContext(ctx),
+		Timestamp:  time.Now().UTC(),
+		Changes:    diffDocuments(before, after),
+	}
+}
+
+// Audit failure policies, chosen with AUDIT_FAILURE_POLICY.
+const (
+	// auditBestEffort logs a failed audit write and lets the mutation succeed.
+	auditBestEffort = "BEST_EFFORT"
+	// auditStrict fails the request when its audit entry cannot be written.
+	auditStrict = "STRICT"
+)
+
+// errAuditFailed is returned under the STRICT policy when an audit write fails.
+var errAuditFailed = errors.New("failed to write audit entry")
+
+// auditFailurePolicy returns AUDIT_FAILURE_POLICY, defaulting to BEST_EFFORT.
+// Unknown values fall back to the default with a warning.
+func auditFailurePolicy() string {
+	value := strings.ToUpper(strings.TrimSpace(os.Getenv("AUDIT_FAILURE_POLICY")))
+	switch value {
+	case auditStrict, auditBestEffort:
+		return value
+	case "":
+		return auditBestEffort
+	default:
+		log.Printf("Warning: unknown AUDIT_FAILURE_POLICY %q, using %s", value, auditBestEffort)
+		return auditBestEffort
+	}
+}
+
+// auditFailure applies the failure policy to a failed audit write.
+//
+// Under STRICT the returned error fails the request. Mutations that run in a
+// transaction (see withTransaction) are then rolled back; other writes have
+// already been applied and stay applied, but the caller gets an error instead
+// of a success without a trail.
+func auditFailure(what string, err error) error {
+	if auditFailurePolicy() == auditStrict {
+		log.Printf("Error: failed to write %s: %v", what, err)
+		return fmt.Errorf("%w (%s): %v", errAuditFailed, what, err)
+	}
+	log.Printf("Warning: failed to write %s: %v", what, err)
+	return nil
+}
+
+// pendingAudit holds the audit entries of a transaction that withTransaction
+// writes after the commit.
+type pendingAudit struct {
+	entries []auditEntry
+}
+
+type pendingAuditKey struct{}
+
+// withTransaction runs fn in a transaction on session, as
+// session.WithTransaction does. Under STRICT the audit entries fn records are
+// written inside the transaction, so a failed write rolls the mutation back.
+// Under BEST_EFFORT they are held back and written after the commit: a failed
+// insert aborts the server-side transaction even when its error is ignored,
+// and the commit would then fail.
+func withTransaction(ctx context.Context, session *mongo.Session, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	var pending *pendingAudit
+	result, err := session.WithTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+		// The callback is retried on transient errors; drop the entries of an
+		// aborted attempt.
+		pending = &pendingAudit{}
+		return fn(context.WithValue(ctx, pendingAuditKey{}, pending))
+	})
+	if err != nil {
+		return result, err
+	}
+	return result, recordAuditMany(ctx, pending.entries)
+}
+
+// deferAudit holds entries back for withTransaction to write after the commit,
+// and reports whether it did so.
+func deferAudit(ctx context.Context, entries ...auditEntry) bool {
+	pending, ok := ctx.Value(pendingAuditKey{}).(*pendingAudit)
+	if !ok || auditFailurePolicy() == auditStrict {
+		return false
+	}
+	pending.entries = append(pending.entries, entries...)
+	return true
+}
+
+// recordAudit stores an audit entry for one employee mutation. What happens
+// when that fails depends on the policy; see auditFailure.
+func recordAudit(ctx context.Context, action string, employeeID bson.ObjectID, before, after bson.M) error {
+	entry := newAuditEntry(ctx, action, employeeID, before, after)
+	if deferAudit(ctx, entry) {
+		return nil
+	}
+	if _, err := auditCollection.InsertOne(ctx, entry); err != nil {
+		return auditFailure(fmt.Sprintf("audit entry (%s %s)", action, employeeID.Hex()), err)
+	}
+	return nil
+}
+
+// recordAuditMany stores entries in one round trip; see recordAudit.
+func recordAuditMany(ctx context.Context, entries []auditEntry) error {
+	if len(entries) == 0 || deferAudit(ctx, entries...) {
+		return nil
+	}
+	if _, err := auditCollection.InsertMany(ctx, entries, options.InsertMany().SetOrdered(false)); err != nil {
+		return auditFailure(fmt.Sprintf("%d audit entries", len(entries)), err)
+	}
+	return nil
+}
+
+// diffDocuments lists the fields whose values differ between before and after,
+// sorted by field name. Encrypted fields are compared decrypted, as phones get
+// a new ciphertext on every write even when unchanged.
+func diffDocuments(before, after bson.M) []auditChange {
+	fields := map[string]bool{}
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		if !auditIgnoredFields[field] {
+			names = append(names, field)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []auditChange{}
+	for _, field := range names {
+		oldValue, newValue := before[field], after[field]
+		if !reflect.DeepEqual(oldValue, newValue) && !samePlaintext(field, oldValue, newValue) {
+			changes = append(changes, auditChange{Field: field, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	return changes
+}
+
+// samePlaintext reports whether two stored values of an encrypted field hold
+// the same plaintext.
+func samePlaintext(field string, oldValue, newValue interface{}) bool {
+	if !contains(encryptedFields, field) {
+		return false
+	}
+	oldString, ok := oldValue.(string)
+	if !ok {
+		return false
+	}
+	newString, ok := newValue.(string)
+	return ok && decryptValue(oldString) == decryptValue(newString)
+}
+
+// toDocument converts a model into the raw document form it is stored as.
+func toDocument(v interface{}) (bson.M, error) {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding document: %w", err)
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error decoding document: %w", err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+)
+
+// Role identifies what an authenticated caller is allowed to see and do.
+type Role string
+
+const (
+	RoleAdmin   Role = "admin"
+	RoleFinance Role = "finance"
+	RoleViewer  Role = "viewer"
+)
+
+// Principal is the authenticated caller attached to a request context.
+type Principal struct {
+	Name string
+	Role Role
+}
+
+type principalKey struct{}
+
+// loadAPIKeys parses API_KEYS, a comma-separated list of name:role:key entries,
+// into a lookup table keyed by the secret key.
+func loadAPIKeys() map[string]Principal {
+	keys := make(map[string]Principal)
+	for _, entry := range strings.Split(os.Getenv("API_KEYS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[2] == "" {
+			log.Printf("Warning: ignoring malformed API_KEYS entry for %q", parts[0])
+			continue
+		}
+		keys[parts[2]] = Principal{Name: parts[0], Role: Role(parts[1])}
+	}
+	return keys
+}
+
+// Authenticate resolves the caller from an "Authorization: Bearer <key>" header.
+// Requests without credentials continue anonymously; unknown keys get a 401.
+func Authenticate(next http.Handler) http.Handler {
+	keys := loadAPIKeys()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Responses differ per caller (e.g. masked PII), so shared caches must key on it.
+		w.Header().Add("Vary", "Authorization")
+
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		principal, known := keys[strings.TrimSpace(token)]
+		if !ok || !known {
+			writeError(w, http.StatusUnauthorized, "Invalid or unknown API key")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), principalKey{}, principal)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequireRole wraps next so that only authenticated callers with one of roles may
+// call it. Anonymous callers get 401 and other roles get 403.
+func RequireRole(next http.HandlerFunc, roles ...Role) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := principalFromRequest(r)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+		for _, role := range roles {
+			if principal.Role == role {
+				next(w, r)
+				return
+			}
+		}
+		writeError(w, http.StatusForbidden, "Insufficient permissions")
+	}
+}
+
+// principalFromRequest returns the authenticated caller, if any.
+func principalFromRequest(r *http.Request) (Principal, bool) {
+	principal, ok := r.Context().Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// canViewPII reports whether the caller may see unmasked contact details.
+func canViewPII(r *http.Request) bool {
+	principal, ok := principalFromRequest(r)
+	return ok && principal.Role == RoleAdmin
+}
+
+// maskEmployee obscures the phone and email of an employee, drops the salary
+// and reduces the address to its country, for callers that are not allowed to
+// see personal details.
+func maskEmployee(employee models.Employee) models.Employee {
+	employee.Phone = maskPhone(employee.Phone)
+	employee.Email = maskEmail(employee.Email)
+	employee.Salary = nil
+	if employee.Address != nil {
+		employee.Address = &models.Address{Country: employee.Address.Country}
+	}
+	return employee
+}
+
+// maskPhone keeps only the last four digits, e.g. "***-**-1234".
+func maskPhone(phone string) string {
+	digits := phoneDigits(phone)
+	if len(digits) < 4 {
+		return "***-**-****"
+	}
+	return "***-**-" + digits[len(digits)-4:]
+}
+
+// maskEmail keeps the first character of the local part and the domain,
+// e.g. "j***@example.com".
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return "***"
+	}
+	first, size := utf8.DecodeRuneInString(local)
+	if first == utf8.RuneError && size <= 1 {
+		return "***@" + domain
+	}
+	return string(first) + "***@" + domain
+}
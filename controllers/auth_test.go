@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+)
+
+func TestMaskEmployeeKeepsOnlyAddressCountry(t *testing.T) {
+	address := &models.Address{
+		Street:     "1 Main St",
+		City:       "Springfield",
+		State:      "IL",
+		PostalCode: "62701",
+		Country:    "US",
+	}
+	employee := models.Employee{Name: "Jane Doe", Address: address}
+
+	masked := maskEmployee(employee)
+
+	want := models.Address{Country: "US"}
+	if masked.Address == nil || *masked.Address != want {
+		t.Fatalf("masked address = %+v, want %+v", masked.Address, want)
+	}
+	if address.Street != "1 Main St" || address.City != "Springfield" {
+		t.Errorf("maskEmployee modified the caller's address: %+v", address)
+	}
+}
+
+func TestMaskEmployeeWithoutAddress(t *testing.T) {
+	masked := maskEmployee(models.Employee{Name: "Jane Doe"})
+	if masked.Address != nil {
+		t.Errorf("masked address = %+v, want nil", masked.Address)
+	}
+}
@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Batch operation names, as sent in the op field.
+const (
+	batchCreate = "create"
+	batchUpdate = "update"
+	batchDelete = "delete"
+)
+
+// batchStep is a validated batch operation, ready to apply.
+type batchStep struct {
+	op       string
+	id       bson.ObjectID
+	employee models.Employee
+	reason   string
+}
+
+// batchResult reports one applied operation; id is the new id for creates.
+type batchResult struct {
+	Op string `json:"op"`
+	ID string `json:"id"`
+}
+
+// BatchEmployees - HTTP handler to apply a list of creates, updates and deletes at once
+//
+// The body is an array of {"op", "id", "data", "reason"}; see
+// models.BatchOperation. Data is validated as by CreateEmployee for creates
+// and as by UpdateEmployee, following UPDATE_VALIDATION, for updates, and
+// every operation is checked before anything is written, with errors naming
+// the operation by its index. The operations are then applied in order in one
+// transaction, which requires MongoDB to run as a replica set: if any fails,
+// e.g. on a taken email or a missing employee, none is kept. The response
+// lists {op, id} per operation, in order. At most BULK_MAX_ITEMS operations
+// are allowed.
+func BatchEmployees(w http.ResponseWriter, r *http.Request) {
+	var ops []models.BatchOperation
+	if err := json.NewDecoder(r.Body).Decode(&ops); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+	if len(ops) == 0 {
+		writeError(w, http.StatusBadRequest, "Request body must contain at least one operation")
+		return
+	}
+	if err := checkArraySizes(map[string]int{"operations": len(ops)}); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	steps := make([]batchStep, len(ops))
+	for i, op := range ops {
+		step, status, err := prepareBatchStep(r.Context(), op)
+		if err != nil {
+			writeError(w, status, fmt.Sprintf("operations[%d]: %v", i, err))
+			return
+		}
+		steps[i] = step
+	}
+
+	results, err := applyBatch(r.Context(), steps)
+	if err != nil {
+		var status int
+		switch {
+		case errors.Is(err, errInvalidManager):
+			status = http.StatusBadRequest
+		case errors.Is(err, errEmployeeNotFound):
+			status = http.StatusNotFound
+		case mongo.IsDuplicateKeyError(err):
+			status = http.StatusConflict
+		case errors.Is(err, errDatabaseNotInitialized):
+			writeServerError(w, "Batch rolled back", err)
+			return
+		default:
+			status = http.StatusInternalServerError
+		}
+		writeError(w, status, fmt.Sprintf("Batch rolled back: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Batch applied successfully",
+		"results": results,
+	})
+}
+
+// prepareBatchStep validates one operation and decodes its data. On error it
+// also returns the status to answer with.
+func prepareBatchStep(ctx context.Context, op models.BatchOperation) (batchStep, int, error) {
+	step := batchStep{op: op.Op, reason: strings.TrimSpace(op.Reason)}
+	if err := validate.Struct(op); err != nil {
+		return step, http.StatusUnprocessableEntity, batchValidationError(err)
+	}
+
+	if op.Op == batchCreate {
+		if op.ID != "" {
+			return step, http.StatusBadRequest, errors.New("create does not take an id")
+		}
+	} else {
+		id, err := bson.ObjectIDFromHex(op.ID)
+		if err != nil {
+			return step, http.StatusBadRequest, fmt.Errorf("%w: %q", errInvalidEmployeeID, op.ID)
+		}
+		step.id = id
+	}
+
+	if op.Op == batchDelete {
+		return step, 0, nil
+	}
+	if data := bytes.TrimSpace(op.Data); len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return step, http.StatusBadRequest, fmt.Errorf("%s requires data", op.Op)
+	}
+
+	var err error
+	if op.Op == batchCreate {
+		step.employee, err = decodeBatchCreate(op.Data)
+	} else {
+		step.employee, err = decodeBatchUpdate(op.Data)
+	}
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		return step, http.StatusUnprocessableEntity, batchValidationError(err)
+	case err != nil:
+		return step, http.StatusBadRequest, err
+	}
+	if err := checkEmailMX(ctx, step.employee.Email); err != nil {
+		return step, http.StatusUnprocessableEntity, err
+	}
+	return step, 0, nil
+}
+
+// decodeBatchCreate decodes and validates the data of a create as
+// CreateEmployee does.
+func decodeBatchCreate(data json.RawMessage) (models.Employee, error) {
+	var employee models.Employee
+	if err := json.Unmarshal(data, &employee); err != nil {
+		return employee, fmt.Errorf("invalid data: %v", err)
+	}
+	applyCreateDefaults(&employee)
+	normalizeEmployee(&employee)
+	return employee, validate.Struct(employee)
+}
+
+// decodeBatchUpdate decodes and validates the data of an update as
+// decodeEmployeeUpdate does.
+func decodeBatchUpdate(data json.RawMessage) (models.Employee, error) {
+	if !partialUpdates() {
+		var employee models.Employee
+		if err := json.Unmarshal(data, &employee); err != nil {
+			return employee, fmt.Errorf("invalid data: %v", err)
+		}
+		normalizeEmployee(&employee)
+		return employee, validate.Struct(employee)
+	}
+
+	var update models.EmployeeUpdate
+	if err := json.Unmarshal(data, &update); err != nil {
+		return models.Employee{}, fmt.Errorf("invalid data: %v", err)
+	}
+	if reflect.ValueOf(update).IsZero() {
+		return models.Employee{}, errors.New("data must set at least one field")
+	}
+	normalizeContact(&update.Email, &update.Phone)
+	if err := validate.Struct(update); err != nil {
+		return models.Employee{}, err
+	}
+	return update.Employee(), nil
+}
+
+// batchValidationError formats a validate.Struct error like writeValidationError.
+func batchValidationError(err error) error {
+	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		return errors.New(formatValidationErrors(validationErrors))
+	}
+	return fmt.Errorf("validation error: %v", err)
+}
+
+// applyBatch applies steps in order inside a transaction, with the same
+// insert, update and delete functions as the single-employee handlers, and
+// stops at the first failure, which aborts the transaction.
+func applyBatch(ctx context.Context, steps []batchStep) ([]batchResult, error) {
+	var results []batchResult
+
+	session, err := startSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = withTransaction(ctx, session, func(ctx context.Context) (interface{}, error) {
+		// The callback is retried on transient errors, so start over each time.
+		results = make([]batchResult, 0, len(steps))
+		for i, step := range steps {
+			id, err := applyBatchStep(ctx, step)
+			if err != nil {
+				return nil, fmt.Errorf("operations[%d]: %w", i, err)
+			}
+			results = append(results, batchResult{Op: step.op, ID: id.Hex()})
+		}
+		return nil, nil
+	})
+	// The steps invalidate what they touch, but a read between a step and the
+	// commit may have cached the version from before the batch; drop everything.
+	detailCache().purge()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Applied a batch of %d operations\n", len(steps))
+	return results, nil
+}
+
+// applyBatchStep applies one step and returns the id of the employee it wrote.
+func applyBatchStep(ctx context.Context, step batchStep) (bson.ObjectID, error) {
+	switch step.op {
+	case batchCreate:
+		if err := checkManagerAssignment(ctx, step.employee, nil); err != nil {
+			return bson.NilObjectID, err
+		}
+		return insertOneEmployee(ctx, step.employee)
+	case batchUpdate:
+		// As in UpdateEmployee, a partial update may change the manager without
+		// restating the department it is checked against. The document is read
+		// directly, not through detailCache, to see this transaction's writes.
+		checked := step.employee
+		if checked.ManagerID != nil && checked.Department == "" {
+			if doc, err := findEmployeeDocument(ctx, step.id); err == nil {
+				var current models.Employee
+				if err := decodeDocument(doc, &current); err == nil {
+					checked.Department = current.Department
+				}
+			}
+		}
+		if err := checkManagerAssignment(ctx, checked, &step.id); err != nil {
+			return step.id, err
+		}
+		return step.id, updateOneEmployee(ctx, step.id, step.employee, nil)
+	default:
+		return step.id, deleteOneEmployee(ctx, step.id, step.reason)
+	}
+}
@@ -0,0 +1,285 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// parseDryRun reads ?dryRun=. Bulk handlers given dryRun=true report what they
+// would change without writing anything.
+func parseDryRun(r *http.Request) (bool, error) {
+	raw := r.URL.Query().Get("dryRun")
+	if raw == "" {
+		return false, nil
+	}
+	dryRun, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, errors.New("dryRun must be true or false")
+	}
+	return dryRun, nil
+}
+
+// writeDryRun responds with the employees matching filter, as a preview of a
+// bulk operation: {"dryRun": true, "matched": n, "employees": [...]}. The list
+// is capped like the employee list; matched is always the full count.
+func writeDryRun(w http.ResponseWriter, r *http.Request, filter bson.M) {
+	employees, truncated, err := getAllEmployees(r.Context(), filter, nil)
+	if err != nil {
+		writeServerError(w, "Failed to preview operation", err)
+		return
+	}
+	matched := int64(len(employees))
+	if truncated {
+		if matched, err = listCollection.CountDocuments(r.Context(), filter); err != nil {
+			writeServerError(w, "Failed to count employees", err)
+			return
+		}
+		w.Header().Set("X-Result-Truncated", "true")
+	}
+	if !canViewPII(r) {
+		for i := range employees {
+			employees[i] = maskEmployee(employees[i])
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"dryRun":    true,
+		"matched":   matched,
+		"employees": employees,
+	})
+}
+
+// checkArraySizes enforces BULK_MAX_ITEMS (default 500) on the arrays of a
+// bulk request body, keyed by field name, so one request cannot name an
+// unbounded number of employees or tags. It runs before any validation or
+// database access.
+func checkArraySizes(arrays map[string]int) error {
+	limit := envInt("BULK_MAX_ITEMS", 500)
+	for _, field := range []string{"ids", "add", "remove", "operations"} {
+		if n, ok := arrays[field]; ok && n > limit {
+			return fmt.Errorf("%s has %d items; at most %d are allowed per request", field, n, limit)
+		}
+	}
+	return nil
+}
+
+// parseObjectIDs converts hex ids, dropping duplicates. Invalid ids are
+// reported together.
+func parseObjectIDs(hexIDs []string) ([]bson.ObjectID, error) {
+	ids := make([]bson.ObjectID, 0, len(hexIDs))
+	seen := map[bson.ObjectID]bool{}
+	var invalid []string
+	for _, hex := range hexIDs {
+		id, err := bson.ObjectIDFromHex(strings.TrimSpace(hex))
+		if err != nil {
+			invalid = append(invalid, hex)
+			continue
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("%w: %s", errInvalidEmployeeID, strings.Join(invalid, ", "))
+	}
+	return ids, nil
+}
+
+// findAffected returns the _id and the named fields of the employees matching
+// filter, so bulk writes can be restricted to exactly those employees and
+// audited per employee.
+func findAffected(ctx context.Context, filter bson.M, fields ...string) ([]bson.M, error) {
+	if err := checkDatabase(); err != nil {
+		return nil, err
+	}
+	projection := bson.M{"_id": 1}
+	for _, field := range fields {
+		projection[field] = 1
+	}
+	cur, err := collection.Find(ctx, filter, options.Find().SetProjection(projection))
+	if err != nil {
+		return nil, fmt.Errorf("error finding employees: %w", err)
+	}
+	defer closeCursor(ctx, cur)
+
+	docs := []bson.M{}
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("error finding employees: %w", err)
+	}
+	return docs, nil
+}
+
+// affectedIDs extracts the _id of each document returned by findAffected.
+func affectedIDs(docs []bson.M) []bson.ObjectID {
+	ids := make([]bson.ObjectID, 0, len(docs))
+	for _, doc := range docs {
+		if id, ok := doc["_id"].(bson.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// BulkUpdateDepartment - HTTP handler to move a list of employees to one department
+func BulkUpdateDepartment(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := parseDryRun(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req models.BulkDepartmentUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+	if err := checkArraySizes(map[string]int{"ids": len(req.IDs)}); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.Department = strings.TrimSpace(req.Department)
+	if err := validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+	ids, err := parseObjectIDs(req.IDs)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := withNotDeleted(bson.M{"_id": bson.M{"$in": ids}})
+	if dryRun {
+		writeDryRun(w, r, filter)
+		return
+	}
+
+	affected, err := findAffected(r.Context(), filter, "department")
+	if err != nil {
+		writeServerError(w, "Failed to update departments", err)
+		return
+	}
+	filter["_id"] = bson.M{"$in": affectedIDs(affected)}
+
+	result, err := collection.UpdateMany(r.Context(), filter, bson.M{
+		"$set": bson.M{"department": req.Department, "updatedAt": time.Now().UTC()},
+	})
+	detailCache().purge()
+	if err != nil {
+		writeServerError(w, "Failed to update departments", err)
+		return
+	}
+	fmt.Printf("Moved %d employees to department %q\n", result.ModifiedCount, req.Department)
+
+	entries := make([]auditEntry, 0, len(affected))
+	for _, doc := range affected {
+		if doc["department"] == req.Department {
+			continue
+		}
+		entries = append(entries, newAuditEntry(r.Context(), auditUpdate, doc["_id"].(bson.ObjectID),
+			bson.M{"department": doc["department"]}, bson.M{"department": req.Department}))
+	}
+	if err := recordAuditMany(r.Context(), entries); err != nil {
+		writeServerError(w, "Failed to update departments", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":  "Departments updated successfully",
+		"matched":  result.MatchedCount,
+		"modified": result.ModifiedCount,
+	})
+}
+
+// BatchDeleteEmployees - HTTP handler to soft-delete a list of employees
+//
+// Each employee is deleted as by DeleteEmployee. Ids that do not exist or are
+// already deleted are reported in notFound.
+func BatchDeleteEmployees(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := parseDryRun(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req models.BatchDelete
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+	if err := checkArraySizes(map[string]int{"ids": len(req.IDs)}); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+	ids, err := parseObjectIDs(req.IDs)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := withNotDeleted(bson.M{"_id": bson.M{"$in": ids}})
+	if dryRun {
+		writeDryRun(w, r, filter)
+		return
+	}
+
+	affected, err := findAffected(r.Context(), filter)
+	if err != nil {
+		writeServerError(w, "Failed to delete employees", err)
+		return
+	}
+	deletedIDs := affectedIDs(affected)
+	filter["_id"] = bson.M{"$in": deletedIDs}
+
+	now := time.Now().UTC()
+	set := bson.M{"deletedAt": now, "deletedBy": actorFromContext(r.Context()), "updatedAt": now}
+	if reason := strings.TrimSpace(req.Reason); reason != "" {
+		set["deletionReason"] = reason
+	}
+	result, err := collection.UpdateMany(r.Context(), filter, bson.M{"$set": set})
+	detailCache().purge()
+	if err != nil {
+		writeServerError(w, "Failed to delete employees", err)
+		return
+	}
+	fmt.Printf("Soft-deleted %d employees\n", result.ModifiedCount)
+
+	deleted := map[bson.ObjectID]bool{}
+	entries := make([]auditEntry, 0, len(deletedIDs))
+	for _, id := range deletedIDs {
+		deleted[id] = true
+		entries = append(entries, newAuditEntry(r.Context(), auditDelete, id, bson.M{}, set))
+	}
+	if err := recordAuditMany(r.Context(), entries); err != nil {
+		writeServerError(w, "Failed to delete employees", err)
+		return
+	}
+
+	notFound := []string{}
+	for _, id := range ids {
+		if !deleted[id] {
+			notFound = append(notFound, id.Hex())
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":  "Employees deleted successfully",
+		"deleted":  result.ModifiedCount,
+		"notFound": notFound,
+	})
+}
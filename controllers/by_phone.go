@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// phoneInputPattern accepts a phone number as typed: digits with an optional
+// leading + and the usual separators.
+var phoneInputPattern = regexp.MustCompile(`^\+?[0-9 ().-]+$`)
+
+// Bounds on the digits of a phone number; E.164 allows at most 15.
+const (
+	minPhoneDigits = 7
+	maxPhoneDigits = 15
+)
+
+// GetEmployeeByPhone - HTTP handler to look up the employee with a given phone number
+//
+// ?phone= may be formatted in any way; only its digits are compared, against
+// the whole of the stored number, unlike the suffix match of ?phone= on
+// /employees. A national number also matches its E.164 form (see formatE164),
+// as stored by the e164_phone transform or /admin/normalize-phones. Answers
+// 404 when no employee has the number and 409 when more than one does. The
+// route requires the admin role: as with the ?phone= filter of /employees,
+// which only applies for admins, the answer would otherwise confirm whose
+// number it is.
+func GetEmployeeByPhone(w http.ResponseWriter, r *http.Request) {
+	raw := strings.TrimSpace(r.URL.Query().Get("phone"))
+	if raw == "" {
+		writeError(w, http.StatusBadRequest, "Query parameter 'phone' is required")
+		return
+	}
+	digits := phoneDigits(raw)
+	if !phoneInputPattern.MatchString(raw) || len(digits) < minPhoneDigits || len(digits) > maxPhoneDigits {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Query parameter 'phone' must be a phone number of %d to %d digits", minPhoneDigits, maxPhoneDigits))
+		return
+	}
+
+	if err := checkDatabase(); err != nil {
+		writeServerError(w, "Failed to retrieve employee", err)
+		return
+	}
+
+	candidates := storedPhoneDigits(digits)
+	if international := phoneDigits(formatE164(raw)); international != digits {
+		candidates = append(candidates, storedPhoneDigits(international)...)
+	}
+	filter := withNotDeleted(bson.M{"phoneDigits": bson.M{"$in": candidates}})
+	cur, err := collection.Find(r.Context(), filter, options.Find().SetLimit(2))
+	if err != nil {
+		writeServerError(w, "Failed to retrieve employee", err)
+		return
+	}
+	defer closeCursor(r.Context(), cur)
+
+	var employees []models.Employee
+	if err := cur.All(r.Context(), &employees); err != nil {
+		writeServerError(w, "Failed to retrieve employee", err)
+		return
+	}
+	switch len(employees) {
+	case 0:
+		writeError(w, http.StatusNotFound, "No employee found with this phone number")
+		return
+	case 2:
+		writeError(w, http.StatusConflict, "More than one employee has this phone number")
+		return
+	}
+
+	employee := employees[0]
+	decryptEmployee(&employee)
+	writeJSON(w, http.StatusOK, employee)
+}
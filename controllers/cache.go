@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"container/list"
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+)
+
+var (
+	cacheHits   = expvar.NewInt("employee_cache_hits")
+	cacheMisses = expvar.NewInt("employee_cache_misses")
+)
+
+func init() {
+	expvar.Publish("employee_cache_hit_ratio", expvar.Func(func() interface{} {
+		hits, misses := cacheHits.Value(), cacheMisses.Value()
+		if hits+misses == 0 {
+			return 0.0
+		}
+		return float64(hits) / float64(hits+misses)
+	}))
+}
+
+// employeeCache is a size-bounded LRU cache of employees by hex ID with a TTL.
+//
+// Writes invalidate entries, and every invalidation bumps an epoch: a reader
+// that loaded a document from Mongo may only store it if no write happened
+// since it started the load, so a concurrent write can never be overwritten by
+// the stale copy. The cache is per process; other instances may serve an entry
+// for up to the TTL after a write they did not see.
+type employeeCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	epoch   uint64
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	id       string
+	employee models.Employee
+	expires  time.Time
+}
+
+// detailCache fronts getOneEmployee. It is sized by EMPLOYEE_CACHE_SIZE
+// (default 1000) with entries living EMPLOYEE_CACHE_TTL (default 30s);
+// setting EMPLOYEE_CACHE_TTL=0 disables it.
+var detailCache = sync.OnceValue(func() *employeeCache {
+	return newEmployeeCache(envInt("EMPLOYEE_CACHE_SIZE", 1000), envDuration("EMPLOYEE_CACHE_TTL", 30*time.Second))
+})
+
+func newEmployeeCache(size int, ttl time.Duration) *employeeCache {
+	return &employeeCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *employeeCache) enabled() bool {
+	return c.size > 0 && c.ttl > 0
+}
+
+// get returns the cached employee and the current epoch, which must be passed
+// back to put when the caller loads the employee itself.
+func (c *employeeCache) get(id string) (models.Employee, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[id]; ok {
+		entry := elem.Value.(*cacheEntry)
+		if time.Now().Before(entry.expires) {
+			c.order.MoveToFront(elem)
+			cacheHits.Add(1)
+			return entry.employee, c.epoch, true
+		}
+		c.removeElement(elem)
+	}
+	cacheMisses.Add(1)
+	return models.Employee{}, c.epoch, false
+}
+
+// put stores employee unless a write happened since epoch was obtained.
+func (c *employeeCache) put(id string, employee models.Employee, epoch uint64) {
+	if !c.enabled() {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if epoch != c.epoch {
+		return
+	}
+	if elem, ok := c.entries[id]; ok {
+		c.removeElement(elem)
+	}
+	c.entries[id] = c.order.PushFront(&cacheEntry{id: id, employee: employee, expires: time.Now().Add(c.ttl)})
+	for c.order.Len() > c.size {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// invalidate drops id from the cache.
+func (c *employeeCache) invalidate(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+	if elem, ok := c.entries[id]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// purge drops every entry, for writes that touch many employees at once.
+func (c *employeeCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+}
+
+func (c *employeeCache) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry).id)
+}
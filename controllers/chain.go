@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// GetReportingChain - HTTP handler to get an employee and their management chain
+//
+// The response lists the employee first, then their manager, that manager's
+// manager and so on up to the top-level manager. The chain stops at a manager
+// that no longer exists. If the data contains a reporting cycle the chain is
+// cut before the first repeat and cycleDetected is true.
+func GetReportingChain(w http.ResponseWriter, r *http.Request) {
+	chain, cycle, err := reportingChain(r.Context(), employeeIDFromRequest(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, errEmployeeNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to retrieve reporting chain: %v", err))
+		}
+		return
+	}
+
+	if !canViewPII(r) {
+		for i := range chain {
+			chain[i] = maskEmployee(chain[i])
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":          chain,
+		"cycleDetected": cycle,
+	})
+}
+
+// reportingChain returns the employee with the given ID followed by their
+// managers, nearest first, and whether a cycle was found. The managers are
+// fetched with one $graphLookup, which visits each document at most once and
+// so terminates on cycles too.
+func reportingChain(ctx context.Context, id bson.ObjectID) ([]models.Employee, bool, error) {
+	pipeline := bson.A{
+		bson.M{"$match": withNotDeleted(bson.M{"_id": id})},
+		bson.M{"$graphLookup": bson.M{
+			"from":                    collection.Name(),
+			"startWith":               "$managerId",
+			"connectFromField":        "managerId",
+			"connectToField":          "_id",
+			"as":                      "managers",
+			"restrictSearchWithMatch": notDeleted,
+		}},
+	}
+
+	cur, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, false, fmt.Errorf("error walking reporting chain: %w", err)
+	}
+	defer closeCursor(ctx, cur)
+
+	var results []struct {
+		models.Employee `bson:",inline"`
+		Managers        []models.Employee `bson:"managers"`
+	}
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, false, fmt.Errorf("error walking reporting chain: %w", err)
+	}
+	if len(results) == 0 {
+		return nil, false, fmt.Errorf("%w: %s", errEmployeeNotFound, id.Hex())
+	}
+
+	// The lookup returns the managers unordered; following the managerId links
+	// through them yields the path, and shows where it loops back.
+	start := results[0]
+	byID := make(map[bson.ObjectID]models.Employee, len(start.Managers))
+	for _, manager := range start.Managers {
+		decryptEmployee(&manager)
+		byID[manager.ID] = manager
+	}
+	decryptEmployee(&start.Employee)
+	chain := []models.Employee{start.Employee}
+	seen := map[bson.ObjectID]bool{start.ID: true}
+	current := start.Employee
+	for current.ManagerID != nil {
+		if seen[*current.ManagerID] {
+			return chain, true, nil
+		}
+		manager, ok := byID[*current.ManagerID]
+		if !ok {
+			break
+		}
+		seen[manager.ID] = true
+		chain = append(chain, manager)
+		current = manager
+	}
+	return chain, false, nil
+}
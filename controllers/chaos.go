@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// nonProductionEnvs are the APP_ENV values in which fault injection may run.
+// An unset APP_ENV counts as production, so chaos is never on by accident.
+var nonProductionEnvs = map[string]bool{
+	"development": true,
+	"dev":         true,
+	"local":       true,
+	"test":        true,
+	"staging":     true,
+}
+
+// isNonProduction reports whether APP_ENV names a non-production environment.
+func isNonProduction() bool {
+	return nonProductionEnvs[strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV")))]
+}
+
+// Chaos returns middleware that injects faults for testing clients against a real
+// server:
+//
+//   - CHAOS_DELAY_MS delays every request by that many milliseconds
+//   - CHAOS_ERROR_RATE (0 to 1) answers that fraction of requests with a 500
+//
+// It only takes effect when APP_ENV is a non-production environment
+// (development, dev, local, test or staging); otherwise the settings are
+// ignored with a warning. The settings are read once, when Chaos is called.
+func Chaos() mux.MiddlewareFunc {
+	delay := time.Duration(envInt("CHAOS_DELAY_MS", 0)) * time.Millisecond
+	errorRate := 0.0
+	if raw := strings.TrimSpace(os.Getenv("CHAOS_ERROR_RATE")); raw != "" {
+		rate, err := strconv.ParseFloat(raw, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			log.Printf("Warning: ignoring CHAOS_ERROR_RATE %q: must be between 0 and 1", raw)
+		} else {
+			errorRate = rate
+		}
+	}
+	passThrough := func(next http.Handler) http.Handler { return next }
+	if delay == 0 && errorRate == 0 {
+		return passThrough
+	}
+	if !isNonProduction() {
+		log.Println("Warning: CHAOS_DELAY_MS / CHAOS_ERROR_RATE ignored: APP_ENV is not a non-production environment")
+		return passThrough
+	}
+	log.Printf("Chaos mode is ON: delay=%s error_rate=%g", delay, errorRate)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if delay > 0 {
+				timer := time.NewTimer(delay)
+				select {
+				case <-timer.C:
+				case <-r.Context().Done():
+					timer.Stop()
+					return
+				}
+			}
+			if errorRate > 0 && rand.Float64() < errorRate {
+				writeError(w, http.StatusInternalServerError, "Injected failure (CHAOS_ERROR_RATE)")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
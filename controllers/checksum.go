@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// directoryChecksum is the body of GET /employees/etag.
+type directoryChecksum struct {
+	ETag         string     `json:"etag"`
+	Count        int64      `json:"count"`
+	LastModified *time.Time `json:"lastModified"`
+}
+
+// GetDirectoryChecksum - HTTP handler to get a checksum of the whole directory
+//
+// The checksum changes whenever any employee is created, updated or deleted,
+// so clients can poll it and only re-sync when it differs. It is derived from
+// the number of stored documents, soft-deleted ones included, and the latest
+// updatedAt, which every write bumps; both are served from indexes, so the
+// call stays cheap on large collections. It is returned in the body and as
+// the ETag header, and If-None-Match is answered with 304.
+func GetDirectoryChecksum(w http.ResponseWriter, r *http.Request) {
+	count, err := listCollection.CountDocuments(r.Context(), bson.M{})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to count employees: %v", err))
+		return
+	}
+
+	var latest struct {
+		UpdatedAt time.Time `bson:"updatedAt"`
+	}
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "updatedAt", Value: -1}}).
+		SetProjection(bson.M{"updatedAt": 1})
+	err = listCollection.FindOne(r.Context(), bson.M{"updatedAt": bson.M{"$exists": true}}, opts).Decode(&latest)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to read last update: %v", err))
+		return
+	}
+
+	checksum := directoryChecksum{Count: count}
+	if !latest.UpdatedAt.IsZero() {
+		lastModified := latest.UpdatedAt.UTC()
+		checksum.LastModified = &lastModified
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+	checksum.ETag = computeETag([]byte(fmt.Sprintf("%d:%d", count, latest.UpdatedAt.UnixNano())))
+
+	writeJSONWithETag(w, r, checksum, checksum.ETag)
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// inFlightRejected counts requests turned away by LimitConcurrency.
+var inFlightRejected = expvar.NewInt("requests_rejected_in_flight")
+
+// LimitConcurrency returns middleware that serves at most
+// MAX_IN_FLIGHT_REQUESTS requests at once, so load spikes queue up in clients
+// instead of as MongoDB operations waiting for a pooled connection. Requests
+// over the limit are answered immediately with 503 and a Retry-After of
+// IN_FLIGHT_RETRY_AFTER seconds (default 1). Unset or 0 means no limit. Routes
+// in exempt, such as the health checks and the long-lived event streams,
+// neither count towards the limit nor are rejected.
+func LimitConcurrency(exempt map[*mux.Route]bool) mux.MiddlewareFunc {
+	limit := envInt("MAX_IN_FLIGHT_REQUESTS", 0)
+	retryAfter := strconv.Itoa(envInt("IN_FLIGHT_RETRY_AFTER", 1))
+	if limit > 0 {
+		fmt.Printf("Limiting in-flight requests to %d\n", limit)
+	}
+	slots := make(chan struct{}, limit)
+
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempt[mux.CurrentRoute(r)] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+				next.ServeHTTP(w, r)
+			default:
+				inFlightRejected.Add(1)
+				w.Header().Set("Retry-After", retryAfter)
+				writeError(w, http.StatusServiceUnavailable, "Server is busy; retry later")
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
+)
+
+// readPreferenceFromEnv parses a read preference mode (primary, primaryPreferred,
+// secondary, secondaryPreferred, nearest) from the named variable. An unset
+// variable returns fallback, which may be nil to inherit the preference already
+// in effect (e.g. from the connection URI).
+//
+// Any mode other than primary only has an effect against a replica set; on a
+// standalone server every read is served by the single node.
+func readPreferenceFromEnv(name string, fallback *readpref.ReadPref) (*readpref.ReadPref, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback, nil
+	}
+	mode, err := readpref.ModeFromString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", name, value, err)
+	}
+	return readpref.New(mode)
+}
+
+// writeConcernFromEnv builds the client write concern from MONGODB_WRITE_CONCERN_W
+// ("majority", a tag name, or a node count) and MONGODB_WRITE_CONCERN_JOURNAL
+// (true/false). It returns nil when neither is set, which keeps the server
+// default (w:1 against a standalone, majority on modern replica sets).
+func writeConcernFromEnv() (*writeconcern.WriteConcern, error) {
+	w := strings.TrimSpace(os.Getenv("MONGODB_WRITE_CONCERN_W"))
+	journal := strings.TrimSpace(os.Getenv("MONGODB_WRITE_CONCERN_JOURNAL"))
+	if w == "" && journal == "" {
+		return nil, nil
+	}
+
+	wc := &writeconcern.WriteConcern{}
+	if w != "" {
+		if n, err := strconv.Atoi(w); err == nil {
+			wc.W = n
+		} else {
+			wc.W = w
+		}
+	}
+	if journal != "" {
+		j, err := strconv.ParseBool(journal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MONGODB_WRITE_CONCERN_JOURNAL %q: %w", journal, err)
+		}
+		wc.Journal = &j
+	}
+	if !wc.IsValid() {
+		return nil, fmt.Errorf("invalid write concern: w=%q journal=%q", w, journal)
+	}
+	return wc, nil
+}
+
+// envInt returns the named variable parsed as a positive integer, or fallback
+// when it is unset or invalid.
+func envInt(name string, fallback int) int {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return n
+}
+
+// envBool returns the named variable parsed as a boolean, or fallback when it
+// is unset or invalid.
+func envBool(name string, fallback bool) bool {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
+// envDuration returns the named variable parsed with time.ParseDuration
+// (e.g. "30s"), or fallback when it is unset or invalid.
+func envDuration(name string, fallback time.Duration) time.Duration {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return fallback
+	}
+	return d
+}
+
+// ServerTimeouts are the timeouts of the HTTP server, read by
+// ServerTimeoutsFromEnv.
+type ServerTimeouts struct {
+	Read       time.Duration
+	ReadHeader time.Duration
+	Write      time.Duration
+	Idle       time.Duration
+}
+
+// ServerTimeoutsFromEnv reads HTTP_READ_TIMEOUT (default 30s, the whole
+// request including its body), HTTP_READ_HEADER_TIMEOUT (5s),
+// HTTP_WRITE_TIMEOUT (60s, from the end of the request headers to the end of
+// the response) and HTTP_IDLE_TIMEOUT (120s, between keep-alive requests), as
+// durations such as "45s". 0 disables a timeout.
+//
+// The event stream and the streamed exports lift the write timeout for their
+// own response (see clearWriteDeadline), so they are not cut off after
+// HTTP_WRITE_TIMEOUT, and NDJSON imports and snapshot restores lift both
+// timeouts (see clearDeadlines) to read large bodies; WebSocket connections
+// set their own deadlines.
+func ServerTimeoutsFromEnv() ServerTimeouts {
+	return ServerTimeouts{
+		Read:       envDuration("HTTP_READ_TIMEOUT", 30*time.Second),
+		ReadHeader: envDuration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+		Write:      envDuration("HTTP_WRITE_TIMEOUT", 60*time.Second),
+		Idle:       envDuration("HTTP_IDLE_TIMEOUT", 120*time.Second),
+	}
+}
+
+// String formats the timeouts for the startup summary.
+func (t ServerTimeouts) String() string {
+	return fmt.Sprintf("read:%s,read_header:%s,write:%s,idle:%s", t.Read, t.ReadHeader, t.Write, t.Idle)
+}
+
+// clearWriteDeadline lifts the server write timeout for the rest of a
+// long-lived response, such as a stream or a large export.
+func clearWriteDeadline(w http.ResponseWriter) {
+	err := http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Warning: could not clear the write deadline: %v", err)
+	}
+}
+
+// clearDeadlines lifts the server read and write timeouts for the rest of a
+// request with a large body, such as an import, and its response.
+func clearDeadlines(w http.ResponseWriter) {
+	rc := http.NewResponseController(w)
+	if err := rc.SetReadDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Warning: could not clear the read deadline: %v", err)
+	}
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Warning: could not clear the write deadline: %v", err)
+	}
+}
+
+// APIPrefix is API_PREFIX, the path the API routes are mounted under, e.g.
+// "/hr/v1"; the default is "/api". It is read once.
+var APIPrefix = sync.OnceValue(func() string {
+	return routePrefixFromEnv("API_PREFIX", "/api")
+})
+
+// OpsPrefix is OPS_PREFIX, the path /health, /health/live and /metrics are
+// mounted under, e.g. "/ops" for /ops/health; the default is none. It is read
+// once.
+var OpsPrefix = sync.OnceValue(func() string {
+	return routePrefixFromEnv("OPS_PREFIX", "")
+})
+
+// routePrefixFromEnv returns the named variable as a route prefix: a path
+// starting with "/", without a trailing one. An unset variable returns
+// fallback, as does an invalid one, with a warning. "/" alone means no prefix.
+func routePrefixFromEnv(name, fallback string) string {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return fallback
+	}
+	value = strings.TrimSpace(value)
+	if value != "" && (!strings.HasPrefix(value, "/") || strings.ContainsAny(value, "{}?#")) {
+		log.Printf("Warning: ignoring %s %q: must be a path starting with /", name, value)
+		return fallback
+	}
+	return strings.TrimRight(value, "/")
+}
@@ -3,32 +3,171 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
 )
 
+var mongoClient *mongo.Client
 var collection *mongo.Collection
+
+// listCollection is the same collection configured for bulk list reads, which may
+// be routed to secondaries via MONGODB_LIST_READ_PREFERENCE.
+var listCollection *mongo.Collection
 var validate *validator.Validate
 
+var (
+	errInvalidEmployeeID = errors.New("invalid employee ID format")
+	errEmployeeNotFound  = errors.New("no employee found with ID")
+
+	errPreconditionFailed = errors.New("employee has been modified since it was last read")
+)
+
+// notDeleted matches employees that have not been soft-deleted, by a delete or a merge.
+var notDeleted = bson.M{"deletedAt": bson.M{"$exists": false}}
+
+// withNotDeleted returns filter restricted to employees that are not soft-deleted.
+func withNotDeleted(filter bson.M) bson.M {
+	combined := bson.M{}
+	for k, v := range filter {
+		combined[k] = v
+	}
+	for k, v := range notDeleted {
+		combined[k] = v
+	}
+	return combined
+}
+
+// withDeleted returns filter without the restriction added by withNotDeleted.
+func withDeleted(filter bson.M) bson.M {
+	combined := bson.M{}
+	for k, v := range filter {
+		if _, ok := notDeleted[k]; !ok {
+			combined[k] = v
+		}
+	}
+	return combined
+}
+
 func init() {
 	// Initialize validator
 	validate = validator.New()
+	validate.RegisterValidation("daterange", validateDateRange)
+	validate.RegisterValidation("postalcode", validatePostalCode)
+	validate.RegisterValidation("department", validateDepartment)
+	validate.RegisterValidation("tag", validateTag)
+}
+
+// validateDepartment implements the "department" tag. When DEPARTMENTS is set to
+// a comma-separated list, only those departments are accepted; otherwise any
+// value passes.
+func validateDepartment(fl validator.FieldLevel) bool {
+	allowed := allowedDepartments()
+	if len(allowed) == 0 {
+		return true
+	}
+	return allowed[fl.Field().String()]
+}
+
+// allowedDepartments parses DEPARTMENTS; an empty result means no restriction.
+func allowedDepartments() map[string]bool {
+	allowed := map[string]bool{}
+	for _, department := range strings.Split(os.Getenv("DEPARTMENTS"), ",") {
+		if department = strings.TrimSpace(department); department != "" {
+			allowed[department] = true
+		}
+	}
+	return allowed
+}
+
+// defaultDepartment returns DEFAULT_DEPARTMENT, the department given to new
+// employees created without one; empty means a department is required.
+func defaultDepartment() string {
+	return strings.TrimSpace(os.Getenv("DEFAULT_DEPARTMENT"))
+}
+
+// checkDefaultDepartment rejects a DEFAULT_DEPARTMENT that DEPARTMENTS does not
+// allow, which would otherwise fail every create relying on it.
+func checkDefaultDepartment() error {
+	department := defaultDepartment()
+	if allowed := allowedDepartments(); department != "" && len(allowed) > 0 && !allowed[department] {
+		return fmt.Errorf("DEFAULT_DEPARTMENT %q is not one of DEPARTMENTS", department)
+	}
+	return nil
+}
+
+// applyCreateDefaults fills the fields of a new employee that have a
+// configured default, before it is validated. A department in the request
+// always wins over DEFAULT_DEPARTMENT; the default only replaces an empty or
+// blank one.
+func applyCreateDefaults(employee *models.Employee) {
+	if strings.TrimSpace(employee.Department) == "" {
+		if department := defaultDepartment(); department != "" {
+			employee.Department = department
+		}
+	}
+}
+
+// postalCodePattern accepts the common international formats: 2-10 letters,
+// digits, spaces or hyphens, starting with a letter or digit.
+var postalCodePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 -]{1,9}$`)
+
+// validatePostalCode implements the "postalcode" tag.
+func validatePostalCode(fl validator.FieldLevel) bool {
+	return postalCodePattern.MatchString(fl.Field().String())
+}
+
+// Bounds for client-supplied dates. A small skew allows for clock drift between
+// the client and server.
+var (
+	minClientDate   = time.Date(1950, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clientClockSkew = 5 * time.Minute
+)
+
+// validateDateRange implements the "daterange" tag: the date must be on or after
+// minClientDate and no later than now plus clientClockSkew.
+func validateDateRange(fl validator.FieldLevel) bool {
+	date, ok := fl.Field().Interface().(time.Time)
+	if !ok {
+		return false
+	}
+	return !date.Before(minClientDate) && !date.After(time.Now().Add(clientClockSkew))
 }
 
 // ConnectToMongoDB establishes a connection to MongoDB
 // Returns an error if connection fails
+//
+// Optional tuning variables:
+//   - MONGODB_READ_PREFERENCE: client-wide read preference (default: the
+//     readPreference option of MONGODB_URI, or primary if it has none)
+//   - MONGODB_LIST_READ_PREFERENCE: read preference for list reads such as
+//     GetAllEmployees (default: same as MONGODB_READ_PREFERENCE); use
+//     secondaryPreferred to offload them to replicas
+//   - MONGODB_WRITE_CONCERN_W / MONGODB_WRITE_CONCERN_JOURNAL: write concern
+//     for all writes (default: server default)
+//
+// DEFAULT_PAGE_SIZE, MAX_PAGE_SIZE, ENCRYPTION_KEY, DEFAULT_DEPARTMENT and
+// UNIQUE_KEY are checked here too, as they come from the same .env file; see
+// pageSizesFromEnv, fieldCipherFromEnv, checkDefaultDepartment and
+// uniqueKeyFromEnv.
+//
+// Non-primary read preferences require a replica set and may return slightly
+// stale data.
 func ConnectToMongoDB() error {
 	// Load .env file
 	err := godotenv.Load()
@@ -45,7 +184,41 @@ func ConnectToMongoDB() error {
 		return fmt.Errorf("missing required MongoDB environment variables")
 	}
 
+	readPref, err := readPreferenceFromEnv("MONGODB_READ_PREFERENCE", nil)
+	if err != nil {
+		return err
+	}
+	listReadPref, err := readPreferenceFromEnv("MONGODB_LIST_READ_PREFERENCE", readPref)
+	if err != nil {
+		return err
+	}
+	writeConcern, err := writeConcernFromEnv()
+	if err != nil {
+		return err
+	}
+	defaultSize, maxSize, err := pageSizesFromEnv()
+	if err != nil {
+		return err
+	}
+	defaultPageSize, maxPageSize = defaultSize, maxSize
+	if fieldEncryption, err = fieldCipherFromEnv(); err != nil {
+		return err
+	}
+	if err := checkDefaultDepartment(); err != nil {
+		return err
+	}
+	if uniqueKey, err = uniqueKeyFromEnv(); err != nil {
+		return err
+	}
+
+	// Only override the URI's read preference when one is explicitly configured.
 	clientOptions := options.Client().ApplyURI(connectionString)
+	if readPref != nil {
+		clientOptions.SetReadPreference(readPref)
+	}
+	if writeConcern != nil {
+		clientOptions.SetWriteConcern(writeConcern)
+	}
 	client, err := mongo.Connect(clientOptions)
 	if err != nil {
 		return fmt.Errorf("MongoDB connection error: %w", err)
@@ -55,215 +228,888 @@ func ConnectToMongoDB() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	err = client.Ping(ctx, nil)
+	err = client.Ping(ctx, readpref.Primary())
 	if err != nil {
 		return fmt.Errorf("MongoDB ping error: %w", err)
 	}
 
+	auditColName := os.Getenv("MONGODB_AUDIT_COLLECTION_NAME")
+	if auditColName == "" {
+		auditColName = colName + "_audit"
+	}
+
+	mongoClient = client
 	collection = client.Database(dbName).Collection(colName)
+	openAuditCollection(client.Database(dbName), auditColName)
+	listCollection = client.Database(dbName).Collection(colName, options.Collection().SetReadPreference(listReadPref))
+	dbReady.Store(true)
 	fmt.Println("MongoDB Connection success!")
+
+	connInfo = dbInfo{Database: dbName, Collection: colName}
+	if version, err := serverVersion(ctx, client.Database(dbName)); err != nil {
+		log.Println("Warning:", err)
+	} else {
+		connInfo.ServerVersion = version
+	}
+	// Without its index UNIQUE_KEY would not be enforced, so existing
+	// duplicates must be resolved before the API can start.
+	if err := ensureUniqueKeyIndex(ctx); err != nil {
+		return err
+	}
+	// Other failed index builds should not keep the API down; they are
+	// reported in the startup summary instead.
+	if err := ensureIndexes(ctx); err != nil {
+		log.Println("Warning:", err)
+	} else {
+		connInfo.IndexesCreated = true
+	}
+	if err := backfillNameSearch(ctx); err != nil {
+		log.Println("Warning:", err)
+	}
+	if err := backfillPhoneDigits(ctx); err != nil {
+		log.Println("Warning:", err)
+	}
 	return nil
 }
 
+// writeValidationError responds 422 Unprocessable Entity for a well-formed payload
+// that failed validate.Struct. Malformed JSON is reported separately as 400.
+func writeValidationError(w http.ResponseWriter, err error) {
+	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		writeError(w, http.StatusUnprocessableEntity, formatValidationErrors(validationErrors))
+		return
+	}
+	writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Validation error: %v", err))
+}
+
 // formatValidationErrors converts validator errors into a user-friendly string.
 func formatValidationErrors(errs validator.ValidationErrors) string {
 	var errMsgs []string
 	for _, err := range errs {
-		// Provide more user-friendly messages based on the validation tag
-		field := err.Field()
-		tag := err.Tag()
-		param := err.Param()
-
-		var msg string
-		switch tag {
-		case "required":
-			msg = fmt.Sprintf("Field '%s' is required", field)
-		case "min":
-			msg = fmt.Sprintf("Field '%s' must be at least %s", field, param)
-		case "max":
-			msg = fmt.Sprintf("Field '%s' must be at most %s", field, param)
-		case "gt":
-			msg = fmt.Sprintf("Field '%s' must be greater than %s", field, param)
-		case "gte":
-			msg = fmt.Sprintf("Field '%s' must be greater than or equal to %s", field, param)
-		case "lt":
-			msg = fmt.Sprintf("Field '%s' must be less than %s", field, param)
-		case "lte":
-			msg = fmt.Sprintf("Field '%s' must be less than or equal to %s", field, param)
-		case "email":
-			msg = fmt.Sprintf("Field '%s' must be a valid email address", field)
-		// Add more cases for other common validation tags as needed
-		default:
-			msg = fmt.Sprintf("Field '%s' failed validation on the '%s' tag", field, tag)
-		}
-		errMsgs = append(errMsgs, msg)
+		errMsgs = append(errMsgs, validationMessage(err.Field(), err.Tag(), err.Param()))
 	}
 	return strings.Join(errMsgs, ", ")
 }
 
+// validationMessage describes the failure of one validation tag on field.
+func validationMessage(field, tag, param string) string {
+	// Provide more user-friendly messages based on the validation tag
+	var msg string
+	switch tag {
+	case "required":
+		msg = fmt.Sprintf("Field '%s' is required", field)
+	case "min":
+		msg = fmt.Sprintf("Field '%s' must be at least %s", field, param)
+	case "max":
+		msg = fmt.Sprintf("Field '%s' must be at most %s", field, param)
+	case "gt":
+		msg = fmt.Sprintf("Field '%s' must be greater than %s", field, param)
+	case "gte":
+		msg = fmt.Sprintf("Field '%s' must be greater than or equal to %s", field, param)
+	case "lt":
+		msg = fmt.Sprintf("Field '%s' must be less than %s", field, param)
+	case "lte":
+		msg = fmt.Sprintf("Field '%s' must be less than or equal to %s", field, param)
+	case "email":
+		msg = fmt.Sprintf("Field '%s' must be a valid email address", field)
+	case "oneof":
+		msg = fmt.Sprintf("Field '%s' must be one of: %s", field, param)
+	case "postalcode":
+		msg = fmt.Sprintf("Field '%s' must be a valid postal code", field)
+	case "iso3166_1_alpha2":
+		msg = fmt.Sprintf("Field '%s' must be a two-letter ISO 3166 country code", field)
+	case "department":
+		msg = fmt.Sprintf("Field '%s' must be one of the configured departments: %s", field, os.Getenv("DEPARTMENTS"))
+	case "daterange":
+		msg = fmt.Sprintf("Field '%s' must be a date between %s and now", field, minClientDate.Format("2006-01-02"))
+	case "tag":
+		msg = fmt.Sprintf("Field '%s' must be 1-32 letters, digits, '-' or '_'", field)
+	// Add more cases for other common validation tags as needed
+	default:
+		msg = fmt.Sprintf("Field '%s' failed validation on the '%s' tag", field, tag)
+	}
+	return msg
+}
+
 // GetAllEmployees - HTTP handler to get all employees
+//
+// Only active employees are listed unless ?status= says otherwise: pass
+// status=on_leave or status=terminated for those, or status=all for everyone
+// whatever their status.
+//
+// ?includeDeleted=true (admins only) also lists soft-deleted employees, with
+// their deletedAt, deletedBy and deletionReason.
+//
+// Passing ?limit= or ?cursor= switches to cursor pagination: the response
+// becomes an envelope whose pagination.nextCursor fetches the following page.
+// ?sort= orders the results by one or more fields, e.g. sort=-department,name
+// (a leading "-" sorts that field descending). ?includeId=false leaves the
+// ids out, also in paginated responses, whose cursors still work.
+//
+// The query uses the index INDEX_HINTS picks for the filter, if any; see
+// listIndexHint. ?explain=true (admins only) returns the query plan and
+// execution statistics of the query instead of the employees.
 func GetAllEmployees(w http.ResponseWriter, r *http.Request) {
-	employees, err := getAllEmployees()
+	filter, err := buildEmployeeFilter(r.URL.Query(), canViewPII(r))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Failed to retrieve employees: %v", err)})
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defaultToActive(r.URL.Query(), filter)
+
+	filter, ok := parseIncludeDeleted(w, r, filter)
+	if !ok {
+		return
+	}
+
+	sort, ok := parseSortParam(w, r)
+	if !ok {
 		return
 	}
-	json.NewEncoder(w).Encode(employees)
+	includeID, ok := parseIncludeIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	page, paginated, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	explain, ok := parseExplainParam(w, r)
+	if !ok {
+		return
+	}
+	if explain {
+		explainEmployeeList(w, r, filter, sort, page, paginated)
+		return
+	}
+	if paginated {
+		getEmployeesPage(w, r, filter, page, sort, includeID)
+		return
+	}
+
+	employees, truncated, err := getAllEmployees(r.Context(), filter, sort)
+	if err != nil {
+		writeServerError(w, "Failed to retrieve employees", err)
+		return
+	}
+	if !canViewPII(r) {
+		for i := range employees {
+			employees[i] = maskEmployee(employees[i])
+		}
+	}
+	if !includeID {
+		withoutIDs(employees)
+	}
+
+	total := int64(len(employees))
+	if truncated {
+		// Only the first page was returned; tell clients how many exist so they paginate.
+		if total, err = listCollection.CountDocuments(r.Context(), filter); err != nil {
+			writeServerError(w, "Failed to count employees", err)
+			return
+		}
+		w.Header().Set("X-Result-Truncated", "true")
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	w.Header().Add("Vary", "Accept")
+	if mediaType := readMediaType(r); mediaType == mediaTypeXML {
+		writeCacheable(w, r, mediaType, employeeList{Employees: employees}, "")
+		return
+	}
+	writeCacheableJSON(w, r, employees)
+}
+
+// explainEmployeeList writes the plan of the query GetAllEmployees would run.
+// For a paginated request that is the query of the first page: the condition
+// of ?cursor= is left out.
+func explainEmployeeList(w http.ResponseWriter, r *http.Request, filter bson.M, sort *sortSpec, page pageParams, paginated bool) {
+	var order bson.D
+	limit := envInt("LIST_MAX_RESULTS", 1000) + 1
+	if paginated {
+		order, limit = bson.D{{Key: "_id", Value: 1}}, page.Limit+1
+	}
+	if sort != nil {
+		order = sort.withIDTiebreak()
+	}
+
+	plan, err := explainListQuery(r.Context(), filter, order, limit)
+	if err != nil {
+		writeServerError(w, "Failed to explain query", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, plan)
+}
+
+// parseIncludeDeleted applies ?includeDeleted=true, which only admins may
+// pass, to filter. It writes the error response and returns false when the
+// parameter is rejected.
+func parseIncludeDeleted(w http.ResponseWriter, r *http.Request, filter bson.M) (bson.M, bool) {
+	raw := r.URL.Query().Get("includeDeleted")
+	if raw == "" {
+		return filter, true
+	}
+	includeDeleted, err := strconv.ParseBool(raw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Query parameter 'includeDeleted' must be true or false")
+		return nil, false
+	}
+	if !includeDeleted {
+		return filter, true
+	}
+	if principal, ok := principalFromRequest(r); !ok || principal.Role != RoleAdmin {
+		writeError(w, http.StatusForbidden, "includeDeleted requires the admin role")
+		return nil, false
+	}
+	return withDeleted(filter), true
+}
+
+// getEmployeesPage writes one cursor-paginated page of the employee list as
+// {"data": [...], "pagination": {...}}. Pages are ordered by _id, or by sort
+// with _id breaking ties. The cursor is built before ids are dropped for
+// includeID=false, so it does not depend on them being returned.
+func getEmployeesPage(w http.ResponseWriter, r *http.Request, filter bson.M, page pageParams, sort *sortSpec, includeID bool) {
+	employees, info, err := findEmployeesPage(r.Context(), filter, page, sort)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidCursor), errors.Is(err, errCursorSortMismatch):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeServerError(w, "Failed to retrieve employees", err)
+		}
+		return
+	}
+	if info.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", info.NextCursor)
+	}
+
+	if !canViewPII(r) {
+		for i := range employees {
+			employees[i] = maskEmployee(employees[i])
+		}
+	}
+	if !includeID {
+		withoutIDs(employees)
+	}
+
+	w.Header().Add("Vary", "Accept")
+	if mediaType := readMediaType(r); mediaType == mediaTypeXML {
+		writeCacheable(w, r, mediaType, employeeList{Employees: employees}, "")
+		return
+	}
+	writeCacheableJSON(w, r, map[string]interface{}{
+		"data":       employees,
+		"pagination": info,
+	})
+}
+
+// findEmployeesPage reads one page of the employees matching filter, ordered
+// as getEmployeesPage describes, and returns them decrypted along with the
+// cursor of the next page. A cursor that does not fit the request is reported
+// as errInvalidCursor or errCursorSortMismatch.
+func findEmployeesPage(ctx context.Context, filter bson.M, page pageParams, sort *sortSpec) ([]models.Employee, pageInfo, error) {
+	info := pageInfo{Limit: page.Limit}
+	if err := checkDatabase(); err != nil {
+		return nil, info, err
+	}
+	order := bson.D{{Key: "_id", Value: 1}}
+	if sort != nil {
+		order = sort.withIDTiebreak()
+	}
+
+	if page.After != nil {
+		var sortRaw string
+		if sort != nil {
+			sortRaw = sort.Raw
+		}
+		if page.After.Sort != sortRaw {
+			return nil, info, errCursorSortMismatch
+		}
+		if sort == nil {
+			filter["_id"] = bson.M{"$gt": page.After.ID}
+		} else {
+			values, err := decodeSortValues(page.After.Values)
+			if err != nil || len(values) != len(order) {
+				return nil, info, errInvalidCursor
+			}
+			and, _ := filter["$and"].(bson.A)
+			filter["$and"] = append(and, sort.afterFilter(values))
+		}
+	}
+
+	opts := options.Find().SetSort(order).SetLimit(int64(page.Limit) + 1)
+	if hint := listIndexHint(filter); hint != "" {
+		opts.SetHint(hint)
+	}
+	cur, err := listCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, info, fmt.Errorf("error finding employees: %w", err)
+	}
+	defer closeCursor(ctx, cur)
+
+	employees := []models.Employee{}
+	if err := cur.All(ctx, &employees); err != nil {
+		return nil, info, fmt.Errorf("error decoding employees: %w", err)
+	}
+
+	if len(employees) > page.Limit {
+		employees = employees[:page.Limit]
+		info.HasMore = true
+		last := employees[len(employees)-1]
+		next := pageCursor{ID: last.ID}
+		if sort != nil {
+			doc, err := toDocument(last)
+			if err != nil {
+				return nil, info, fmt.Errorf("error building cursor: %w", err)
+			}
+			next.Sort = sort.Raw
+			next.Values = encodeSortValues(sort.sortValues(doc))
+		}
+		info.NextCursor = encodeCursor(next)
+	}
+
+	// Decrypted only now, as the cursor holds the stored sort values.
+	decryptEmployees(employees)
+	return employees, info, nil
+}
+
+// GetEmployee - HTTP handler to get a single employee by ID
+//
+// ?includeId=false leaves the id out of the body; the ETag stays that of the
+// stored version, so If-None-Match and If-Match work the same either way.
+func GetEmployee(w http.ResponseWriter, r *http.Request) {
+	employeeID := employeeIDFromRequest(r)
+
+	includeID, ok := parseIncludeIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	employee, err := getOneEmployee(r.Context(), employeeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errEmployeeNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeServerError(w, "Failed to retrieve employee", err)
+		}
+		return
+	}
+
+	etag := employeeETag(employee)
+	if !canViewPII(r) {
+		employee = maskEmployee(employee)
+	}
+	if !includeID {
+		employee.ID = bson.NilObjectID
+	}
+	w.Header().Add("Vary", "Accept")
+	if mediaType := readMediaType(r); mediaType == mediaTypeXML {
+		writeCacheable(w, r, mediaType, employee, "")
+		return
+	}
+	writeJSONWithETag(w, r, employee, etag)
 }
 
 // CreateEmployee - HTTP handler to create a new employee
+//
+// Uniqueness is enforced by the unique index on UNIQUE_KEY (default email), not
+// by a lookup before the insert, so of two concurrent creates with the same
+// key exactly one succeeds and the other gets 409 Conflict. The index is built
+// at startup, which fails without it; see ensureUniqueKeyIndex.
+// The email and phone go through NORMALIZE_TRANSFORMS before validation, and an
+// empty department is set to DEFAULT_DEPARTMENT when configured. With
+// VALIDATE_EMAIL_MX=true the email domain must also have mail servers; see
+// checkEmailMX.
 func CreateEmployee(w http.ResponseWriter, r *http.Request) {
 	var employee models.Employee
 
 	err := json.NewDecoder(r.Body).Decode(&employee)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Invalid request payload: %v", err)})
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
 		return
 	}
 
-	if err := validate.Struct(employee); err != nil {
-		if validationErrors, ok := err.(validator.ValidationErrors); ok {
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]string{"error": formatValidationErrors(validationErrors)})
-			return
-		}
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Validation error: %v", err)})
+	if status, msg := prepareCreate(r.Context(), &employee); status != 0 {
+		writeError(w, status, msg)
 		return
 	}
 
-	employeeID, err := insertOneEmployee(employee)
+	employeeID, err := insertOneEmployee(r.Context(), employee)
+	if mongo.IsDuplicateKeyError(err) {
+		writeError(w, http.StatusConflict, duplicateKeyMessage())
+		return
+	}
+	if errors.Is(err, errAuditFailed) {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Employee %s was created but not audited: %v", employeeID.Hex(), err))
+		return
+	}
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Failed to insert employee: %v", err)})
+		writeServerError(w, "Failed to insert employee", err)
 		return
 	}
 
 	employee.ID = employeeID // Set the ID in the employee model
 
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"message": "Employee created successfully",
 		"data":    employee,
 	})
 }
 
+// prepareCreate applies the create rules of CreateEmployee to employee:
+// defaults, NORMALIZE_TRANSFORMS, validation, the MX check and the manager
+// rules. On failure it returns the status a single create answers with and
+// the message for the client; on success the status is 0.
+func prepareCreate(ctx context.Context, employee *models.Employee) (int, string) {
+	applyCreateDefaults(employee)
+	normalizeEmployee(employee)
+	if err := validate.Struct(*employee); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			return http.StatusUnprocessableEntity, formatValidationErrors(validationErrors)
+		}
+		return http.StatusUnprocessableEntity, fmt.Sprintf("Validation error: %v", err)
+	}
+	if err := checkEmailMX(ctx, employee.Email); err != nil {
+		return http.StatusUnprocessableEntity, noMailServersMessage
+	}
+	if err := checkManagerAssignment(ctx, *employee, nil); err != nil {
+		if errors.Is(err, errInvalidManager) {
+			return http.StatusBadRequest, err.Error()
+		}
+		return http.StatusInternalServerError, fmt.Sprintf("Failed to check manager: %v", err)
+	}
+	return 0, ""
+}
+
 // UpdateEmployee - HTTP handler to update an employee
+//
+// Fields omitted from the body keep their stored values. By default the body
+// must still pass the create validation; with UPDATE_VALIDATION=partial only
+// the fields present are validated. NORMALIZE_TRANSFORMS applies as on create.
+// Server-managed fields in the body, such as createdAt or deletedAt, are
+// ignored; see writableFields.
 func UpdateEmployee(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	employeeID := params["id"]
+	employeeID := employeeIDFromRequest(r)
 
-	var employee models.Employee
-	err := json.NewDecoder(r.Body).Decode(&employee)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Invalid request payload: %v", err)})
+	employee, ok := decodeEmployeeUpdate(w, r)
+	if !ok {
 		return
 	}
 
-	if err := validate.Struct(employee); err != nil {
-		if validationErrors, ok := err.(validator.ValidationErrors); ok {
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]string{"error": formatValidationErrors(validationErrors)})
+	// A partial update may change the manager without restating the
+	// department the manager is checked against.
+	checked := employee
+	if checked.ManagerID != nil && checked.Department == "" {
+		if current, err := getOneEmployee(r.Context(), employeeID); err == nil {
+			checked.Department = current.Department
+		}
+	}
+	if err := checkManagerAssignment(r.Context(), checked, &employeeID); err != nil {
+		if errors.Is(err, errInvalidManager) {
+			writeError(w, http.StatusBadRequest, err.Error())
 			return
 		}
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Validation error: %v", err)})
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to check manager: %v", err))
 		return
 	}
 
-	if err := updateOneEmployee(employeeID, employee); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Failed to update employee: %v", err)})
+	// With If-Match, only update when the client has seen the current version.
+	var expected *models.Employee
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		current, err := getOneEmployee(r.Context(), employeeID)
+		if err != nil {
+			switch {
+			case errors.Is(err, errEmployeeNotFound):
+				writeError(w, http.StatusNotFound, err.Error())
+			default:
+				writeServerError(w, "Failed to retrieve employee", err)
+			}
+			return
+		}
+		if !etagMatches(ifMatch, employeeETag(current)) {
+			writeError(w, http.StatusPreconditionFailed, errPreconditionFailed.Error())
+			return
+		}
+		expected = &current
+	}
+
+	if err := updateOneEmployee(r.Context(), employeeID, employee, expected); err != nil {
+		if errors.Is(err, errPreconditionFailed) {
+			writeError(w, http.StatusPreconditionFailed, err.Error())
+			return
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			writeError(w, http.StatusConflict, duplicateKeyMessage())
+			return
+		}
+		if errors.Is(err, errEmployeeNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeServerError(w, "Failed to update employee", err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]string{"message": "Employee updated successfully"})
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Employee updated successfully"})
+}
+
+// UpdateEmployeeStatus - HTTP handler to change only the lifecycle status of an employee
+func UpdateEmployeeStatus(w http.ResponseWriter, r *http.Request) {
+	employeeID := employeeIDFromRequest(r)
+
+	var update models.StatusUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+
+	if err := validate.Struct(update); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	employee, err := updateEmployeeStatus(r.Context(), employeeID, update)
+	if err != nil {
+		switch {
+		case errors.Is(err, errEmployeeNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeServerError(w, "Failed to update employee status", err)
+		}
+		return
+	}
+
+	if !canViewPII(r) {
+		employee = maskEmployee(employee)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Employee status updated successfully",
+		"data":    employee,
+	})
 }
 
 // DeleteEmployee - HTTP handler to delete an employee
+//
+// The employee is soft-deleted: it disappears from every read, but stays
+// stored with deletedAt, deletedBy (the caller) and the optional
+// {"reason": "..."} from the body, visible through ?includeDeleted=true.
 func DeleteEmployee(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	employeeID := params["id"]
+	employeeID := employeeIDFromRequest(r)
+
+	var req models.DeleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
 
-	if err := deleteOneEmployee(employeeID); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Failed to delete employee: %v", err)})
+	if err := deleteOneEmployee(r.Context(), employeeID, strings.TrimSpace(req.Reason)); err != nil {
+		switch {
+		case errors.Is(err, errEmployeeNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeServerError(w, "Failed to delete employee", err)
+		}
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]string{"message": "Employee deleted successfully"})
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Employee deleted successfully"})
 }
 
 // insertOneEmployee inserts an employee into the database and returns an error if any.
-func insertOneEmployee(employee models.Employee) (bson.ObjectID, error) {
-	result, err := collection.InsertOne(context.Background(), employee)
+func insertOneEmployee(ctx context.Context, employee models.Employee) (bson.ObjectID, error) {
+	if err := checkDatabase(); err != nil {
+		return bson.NilObjectID, err
+	}
+	doc := newEmployeeDocument(ctx, employee)
+	doc.ID = bson.NewObjectID()
+
+	result, err := collection.InsertOne(ctx, doc)
 	if err != nil {
 		return bson.NilObjectID, fmt.Errorf("error inserting employee: %w", err)
 	}
 	fmt.Println("Inserted 1 employee with id:", result.InsertedID)
 
-	return result.InsertedID.(bson.ObjectID), nil // Return the inserted ID
+	if after, err := toDocument(doc); err == nil {
+		if err := recordAudit(ctx, auditCreate, doc.ID, nil, after); err != nil {
+			return doc.ID, err
+		}
+	}
+
+	return doc.ID, nil // Return the inserted ID
+}
+
+// newEmployeeDocument fills in the server-managed fields of a new employee,
+// overwriting anything the client supplied for them. createdBy is the caller.
+func newEmployeeDocument(ctx context.Context, employee models.Employee) models.Employee {
+	now := time.Now().UTC()
+	employee.CreatedAt = now
+	employee.CreatedBy = actorFromContext(ctx)
+	employee.UpdatedAt = now
+	employee.LastSeenAt = nil
+	employee.PhoneDigits = phoneDigits(employee.Phone)
+	employee.NameSearch = foldSearchText(employee.Name)
+	employee.Tags = normalizeTags(employee.Tags)
+	if employee.Status == "" {
+		employee.Status = models.StatusActive
+	}
+	encryptEmployee(&employee)
+	return employee
 }
 
 // updateOneEmployee updates an employee document in the database and returns an error if any.
-func updateOneEmployee(employeeID string, employee models.Employee) error {
-	id, err := bson.ObjectIDFromHex(employeeID)
+// Only the fields in writableFields are set, whatever else employee holds.
+// When expected is non-nil the update only applies if the stored document has not
+// changed since expected was read, otherwise errPreconditionFailed is returned.
+func updateOneEmployee(ctx context.Context, id bson.ObjectID, employee models.Employee, expected *models.Employee) error {
+	if err := checkDatabase(); err != nil {
+		return err
+	}
+
+	// Timestamps are server-managed; never trust values from the request body.
+	employee.CreatedAt = time.Time{}
+	employee.UpdatedAt = time.Now().UTC()
+	employee.PhoneDigits = phoneDigits(employee.Phone)
+	employee.NameSearch = foldSearchText(employee.Name)
+	employee.Tags = normalizeTags(employee.Tags)
+	encryptEmployee(&employee)
+
+	set, err := writableSet(employee)
 	if err != nil {
-		return fmt.Errorf("invalid employee ID format: %w", err)
+		return err
 	}
 
-	filter := bson.M{"_id": id}
-	update := bson.M{"$set": employee}
+	filter := withNotDeleted(bson.M{"_id": id})
+	if expected != nil {
+		// Every write bumps updatedAt, so it doubles as the version for the
+		// compare-and-swap and closes the gap between the ETag check and the write.
+		if expected.UpdatedAt.IsZero() {
+			filter["updatedAt"] = bson.M{"$exists": false}
+		} else {
+			filter["updatedAt"] = expected.UpdatedAt
+		}
+	}
+	update := bson.M{"$set": set}
 
-	updateResult, err := collection.UpdateOne(context.Background(), filter, update)
+	var before bson.M
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	err = collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&before)
+	detailCache().invalidate(id.Hex())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		if expected != nil {
+			return errPreconditionFailed
+		}
+		return fmt.Errorf("%w: %s", errEmployeeNotFound, id.Hex())
+	}
 	if err != nil {
 		return fmt.Errorf("error updating employee: %w", err)
 	}
-	fmt.Println("Updated employee with id:", updateResult.UpsertedID)
+	fmt.Println("Updated employee with id:", id.Hex())
+
+	if after, err := findDocument(ctx, id); err == nil {
+		return recordAudit(ctx, auditUpdate, id, before, after)
+	}
 	return nil
 }
 
-// deleteOneEmployee deletes an employee document from the database and returns an error if any.
-func deleteOneEmployee(employeeID string) error {
-	id, err := bson.ObjectIDFromHex(employeeID)
+// updateEmployeeStatus sets only the status and updatedAt fields of an employee and
+// returns the updated document. Terminations also record a termination date,
+// defaulting to now; any other status clears it.
+func updateEmployeeStatus(ctx context.Context, id bson.ObjectID, statusUpdate models.StatusUpdate) (models.Employee, error) {
+	var employee models.Employee
+	if err := checkDatabase(); err != nil {
+		return employee, err
+	}
+
+	now := time.Now().UTC()
+	set := bson.M{"status": statusUpdate.Status, "updatedAt": now}
+	update := bson.M{"$set": set}
+	if statusUpdate.Status == models.StatusTerminated {
+		terminationDate := now
+		if statusUpdate.TerminationDate != nil {
+			terminationDate = statusUpdate.TerminationDate.UTC()
+		}
+		set["terminationDate"] = terminationDate
+	} else {
+		update["$unset"] = bson.M{"terminationDate": ""}
+	}
+
+	var before bson.M
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	err := collection.FindOneAndUpdate(ctx, withNotDeleted(bson.M{"_id": id}), update, opts).Decode(&before)
+	detailCache().invalidate(id.Hex())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return employee, fmt.Errorf("%w: %s", errEmployeeNotFound, id.Hex())
+	}
+	if err != nil {
+		return employee, fmt.Errorf("error updating employee status: %w", err)
+	}
+	fmt.Printf("Updated status of employee with ID %s to %s\n", id.Hex(), statusUpdate.Status)
+
+	after, err := findDocument(ctx, id)
 	if err != nil {
-		return fmt.Errorf("invalid employee ID format: %w", err)
+		return employee, err
+	}
+	if err := recordAudit(ctx, auditStatus, id, before, after); err != nil {
+		return employee, err
+	}
+
+	return employee, decodeDocument(after, &employee)
+}
+
+// deleteOneEmployee soft-deletes an employee, recording who deleted it and why,
+// and returns an error if any.
+func deleteOneEmployee(ctx context.Context, id bson.ObjectID, reason string) error {
+	if err := checkDatabase(); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	set := bson.M{"deletedAt": now, "deletedBy": actorFromContext(ctx), "updatedAt": now}
+	if reason != "" {
+		set["deletionReason"] = reason
 	}
 
-	filter := bson.M{"_id": id}
-	result, err := collection.DeleteOne(context.Background(), filter)
+	var before bson.M
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	err := collection.FindOneAndUpdate(ctx, withNotDeleted(bson.M{"_id": id}), bson.M{"$set": set}, opts).Decode(&before)
+	detailCache().invalidate(id.Hex())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return fmt.Errorf("%w: %s", errEmployeeNotFound, id.Hex())
+	}
 	if err != nil {
 		return fmt.Errorf("error deleting employee: %w", err)
 	}
+	fmt.Printf("Successfully deleted employee with ID: %s\n", id.Hex())
 
-	if result.DeletedCount == 0 {
-		return fmt.Errorf("no employee found with ID: %s", employeeID)
+	if after, err := findDocument(ctx, id); err == nil {
+		return recordAudit(ctx, auditDelete, id, before, after)
 	}
-	fmt.Printf("Successfully deleted employee with ID: %s\n", employeeID)
 	return nil
 }
 
-// getAllEmployees retrieves all employee documents from the database.
-func getAllEmployees() ([]models.Employee, error) {
-	cur, err := collection.Find(context.Background(), bson.M{})
+// findDocument loads an employee, including soft-deleted ones, as a raw document.
+func findDocument(ctx context.Context, id bson.ObjectID) (bson.M, error) {
+	if err := checkDatabase(); err != nil {
+		return nil, err
+	}
+	var doc bson.M
+	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("%w: %s", errEmployeeNotFound, id.Hex())
+	}
 	if err != nil {
-		return nil, fmt.Errorf("error finding employees: %w", err)
+		return nil, fmt.Errorf("error finding employee: %w", err)
 	}
+	return doc, nil
+}
 
-	employees := []models.Employee{}
-	for cur.Next(context.Background()) {
+// decodeDocument decodes a raw employee document into v.
+func decodeDocument(doc bson.M, v interface{}) error {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("error encoding employee: %w", err)
+	}
+	if err := bson.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("error decoding employee: %w", err)
+	}
+	if employee, ok := v.(*models.Employee); ok {
+		decryptEmployee(employee)
+	}
+	return nil
+}
+
+// getOneEmployee retrieves a single employee document by its ID, served from
+// detailCache when possible.
+func getOneEmployee(ctx context.Context, id bson.ObjectID) (models.Employee, error) {
+	var employee models.Employee
+	if err := checkDatabase(); err != nil {
+		return employee, err
+	}
+
+	cache := detailCache()
+	cached, epoch, ok := cache.get(id.Hex())
+	if ok {
+		return cached, nil
+	}
+
+	err := collection.FindOne(ctx, withNotDeleted(bson.M{"_id": id})).Decode(&employee)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return employee, fmt.Errorf("%w: %s", errEmployeeNotFound, id.Hex())
+	}
+	if err != nil {
+		return employee, fmt.Errorf("error finding employee: %w", err)
+	}
+	decryptEmployee(&employee)
+
+	cache.put(id.Hex(), employee, epoch)
+	return employee, nil
+}
+
+// getAllEmployees retrieves employee documents matching filter, capped at
+// LIST_MAX_RESULTS (default 1000). truncated reports whether more documents
+// exist beyond the cap.
+func getAllEmployees(ctx context.Context, filter bson.M, sort *sortSpec) (employees []models.Employee, truncated bool, err error) {
+	if err := checkDatabase(); err != nil {
+		return nil, false, err
+	}
+	maxResults := envInt("LIST_MAX_RESULTS", 1000)
+
+	// Fetch one extra document so we can tell whether the cap cut the result short.
+	opts := options.Find().SetLimit(int64(maxResults) + 1)
+	if sort != nil {
+		opts.SetSort(sort.withIDTiebreak())
+	}
+	if hint := listIndexHint(filter); hint != "" {
+		opts.SetHint(hint)
+	}
+	cur, err := listCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, false, fmt.Errorf("error finding employees: %w", err)
+	}
+	defer closeCursor(ctx, cur)
+
+	employees = []models.Employee{}
+	for cur.Next(ctx) {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
 		var employee models.Employee
 		if err := cur.Decode(&employee); err != nil {
-			return nil, fmt.Errorf("error decoding employee: %w", err)
+			return nil, false, fmt.Errorf("error decoding employee: %w", err)
 		}
+		decryptEmployee(&employee)
 		employees = append(employees, employee)
 	}
 
+	// Next returns false when the context is cancelled; report that as such
+	// rather than as a generic cursor failure.
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
 	if err := cur.Err(); err != nil {
-		return nil, fmt.Errorf("cursor error: %w", err)
+		return nil, false, fmt.Errorf("cursor error: %w", err)
 	}
 
-	return employees, nil
+	if len(employees) > maxResults {
+		return employees[:maxResults], true, nil
+	}
+	return employees, false, nil
+}
+
+// closeCursor releases the server-side cursor. It uses a short-lived context
+// detached from ctx so the cursor is still killed on the server when the
+// request context has already been cancelled (e.g. the client disconnected).
+func closeCursor(ctx context.Context, cur *mongo.Cursor) {
+	closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := cur.Close(closeCtx); err != nil {
+		log.Printf("Warning: error closing cursor: %v", err)
+	}
 }
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// connectTestDatabase connects to MONGODB_TEST_URI, which must be a replica set
+// as writes run in transactions, using a throwaway database that is dropped
+// when the test ends. The test is skipped when the variable is unset.
+func connectTestDatabase(t *testing.T) {
+	t.Helper()
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI not set")
+	}
+
+	dbName := fmt.Sprintf("employees_test_%d", time.Now().UnixNano())
+	t.Setenv("MONGODB_URI", uri)
+	t.Setenv("MONGODB_DB_NAME", dbName)
+	t.Setenv("MONGODB_COLLECTION_NAME", "employees")
+	t.Setenv("UNIQUE_KEY", "")
+	t.Setenv("VALIDATE_EMAIL_MX", "false")
+	if err := ConnectToMongoDB(); err != nil {
+		t.Fatalf("ConnectToMongoDB: %v", err)
+	}
+	t.Cleanup(func() {
+		mongoClient.Database(dbName).Drop(context.Background())
+		mongoClient.Disconnect(context.Background())
+	})
+}
+
+func TestConcurrentCreatesWithSameEmail(t *testing.T) {
+	connectTestDatabase(t)
+
+	const body = `{"name":"Jane Doe","email":"jane.doe@example.com","phone":"555-123-1234","department":"Engineering"}`
+	codes := make([]int, 2)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := httptest.NewRequest(http.MethodPost, "/api/employees", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			CreateEmployee(w, r)
+			codes[i] = w.Code
+		}()
+	}
+	wg.Wait()
+
+	created, conflicts := 0, 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			created++
+		case http.StatusConflict:
+			conflicts++
+		}
+	}
+	if created != 1 || conflicts != 1 {
+		t.Fatalf("status codes = %v, want one 200 and one 409", codes)
+	}
+}
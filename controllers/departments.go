@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// RenameDepartment - HTTP handler to move all employees of one department to another
+//
+// With ?dryRun=true the employees that would move are returned instead.
+func RenameDepartment(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := parseDryRun(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req models.DepartmentRename
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+	req.From = strings.TrimSpace(req.From)
+	req.To = strings.TrimSpace(req.To)
+
+	if err := validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+	if req.From == req.To {
+		writeError(w, http.StatusUnprocessableEntity, "Fields 'from' and 'to' must be different departments")
+		return
+	}
+
+	filter := withNotDeleted(bson.M{"department": req.From})
+	if dryRun {
+		writeDryRun(w, r, filter)
+		return
+	}
+
+	// Collect the affected ids first so every moved employee gets an audit entry.
+	matched, err := findAffected(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to rename department: %v", err))
+		return
+	}
+	ids := affectedIDs(matched)
+	// Restricting to the collected ids keeps the audit trail exact even if
+	// employees join the department concurrently.
+	filter["_id"] = bson.M{"$in": ids}
+
+	result, err := collection.UpdateMany(r.Context(), filter, bson.M{
+		"$set": bson.M{"department": req.To, "updatedAt": time.Now().UTC()},
+	})
+	detailCache().purge()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to rename department: %v", err))
+		return
+	}
+	fmt.Printf("Renamed department %q to %q for %d employees\n", req.From, req.To, result.ModifiedCount)
+
+	entries := make([]auditEntry, 0, len(ids))
+	for _, id := range ids {
+		entries = append(entries, newAuditEntry(r.Context(), auditUpdate, id,
+			bson.M{"department": req.From}, bson.M{"department": req.To}))
+	}
+	if err := recordAuditMany(r.Context(), entries); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to rename department: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":  "Department renamed successfully",
+		"matched":  result.MatchedCount,
+		"modified": result.ModifiedCount,
+	})
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// CheckEmailAvailable - HTTP handler to check whether an email is still free to use
+//
+// This is advisory only: the unique index remains the source of truth, so a
+// create can still be rejected with 409 if the email is taken in between. It
+// only predicts conflicts while UNIQUE_KEY is email, the default.
+func CheckEmailAvailable(w http.ResponseWriter, r *http.Request) {
+	email := strings.TrimSpace(r.URL.Query().Get("email"))
+	if err := validate.Var(email, "required,email"); err != nil {
+		writeError(w, http.StatusBadRequest, "Query parameter 'email' must be a valid email address")
+		return
+	}
+
+	// Soft-deleted employees still hold their email in the unique index, so they
+	// are counted too.
+	count, err := collection.CountDocuments(r.Context(), bson.M{"email": bson.M{"$in": storedEmails(email)}}, options.Count().SetLimit(1))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to check email: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]bool{"available": count == 0})
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// errNoMailServers marks an email address whose domain publishes no MX records.
+var errNoMailServers = errors.New("email domain has no mail servers")
+
+// noMailServersMessage is the 422 message for an email failing checkEmailMX.
+const noMailServersMessage = "Field 'Email' must have a domain that accepts mail"
+
+// emailMXTimeout bounds each MX lookup; see checkEmailMX.
+const emailMXTimeout = 2 * time.Second
+
+// checkEmailMX verifies, when VALIDATE_EMAIL_MX=true, that the domain of email
+// has MX records, returning errNoMailServers if it has none or only the null
+// MX of RFC 7505. It is off by default.
+//
+// The lookup adds a DNS round trip, up to VALIDATE_EMAIL_MX_TIMEOUT (default
+// 2s), to every create and update that sets an email, and is bounded by ctx as
+// well, so a cancelled request stops waiting. A lookup that fails for any other
+// reason, e.g. a timeout or an unreachable resolver, lets the address through
+// with a warning rather than blocking writes on DNS.
+func checkEmailMX(ctx context.Context, email string) error {
+	if email == "" || !envBool("VALIDATE_EMAIL_MX", false) {
+		return nil
+	}
+	_, domain, ok := strings.Cut(email, "@")
+	if !ok || domain == "" {
+		return nil // left to the email tag
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, envDuration("VALIDATE_EMAIL_MX_TIMEOUT", emailMXTimeout))
+	defer cancel()
+	records, err := net.DefaultResolver.LookupMX(ctx, domain)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return errNoMailServers
+	}
+	if err != nil && len(records) == 0 {
+		log.Printf("Warning: MX lookup for %s failed, accepting the email: %v", domain, err)
+		return nil
+	}
+	for _, record := range records {
+		if record.Host != "." && record.Host != "" {
+			return nil
+		}
+	}
+	return errNoMailServers
+}
+
+// emailDomainAccepted is checkEmailMX for handlers: it writes 422 and returns
+// false when the domain of email has no mail servers.
+func emailDomainAccepted(w http.ResponseWriter, r *http.Request, email string) bool {
+	if err := checkEmailMX(r.Context(), email); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, noMailServersMessage)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// encryptedPrefix marks a stored value as encrypted. Values without it are
+// plaintext, written before ENCRYPTION_KEY was set, and are read as they are.
+const encryptedPrefix = "enc:v1:"
+
+// encryptedFields are the fields encrypted at rest when ENCRYPTION_KEY is set.
+var encryptedFields = []string{"email", "phone"}
+
+// fieldEncryption holds the keys derived from ENCRYPTION_KEY; it is nil when
+// encryption is off. It is set by ConnectToMongoDB.
+var fieldEncryption *fieldCipher
+
+// fieldCipher encrypts field values with AES-256-GCM.
+type fieldCipher struct {
+	aead     cipher.AEAD
+	nonceKey []byte
+}
+
+// fieldCipherFromEnv reads ENCRYPTION_KEY, a base64-encoded 32-byte key, and
+// returns nil when it is unset.
+//
+// With a key, emails and phones are encrypted before they are written and
+// decrypted on every read, so the API is unchanged. Emails are encrypted
+// deterministically, the nonce being derived from the value, so the unique
+// index and exact lookups such as /employees/email-available keep working;
+// phones get a random nonce, and their digits, kept in phoneDigits for
+// /employees/by-phone, are encrypted deterministically too. The trade-offs:
+// ?search= and ?q= only match an email by its complete address, ?phone= is
+// rejected with 400 (see errPhoneFilterEncrypted), and sorting by email orders
+// by ciphertext. Documents written before the key was set are encrypted on
+// their next write; until then a plaintext and an encrypted copy
+// of the same email do not collide in the unique index. Losing the key makes
+// the encrypted values unreadable.
+func fieldCipherFromEnv() (*fieldCipher, error) {
+	raw := strings.TrimSpace(os.Getenv("ENCRYPTION_KEY"))
+	if raw == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("ENCRYPTION_KEY must be a base64-encoded 32-byte key")
+	}
+
+	block, err := aes.NewCipher(deriveKey(key, "employee-field-encryption"))
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+	return &fieldCipher{aead: aead, nonceKey: deriveKey(key, "employee-field-nonce")}, nil
+}
+
+// deriveKey derives a purpose-specific subkey, so the encryption key is never
+// also used as a MAC key.
+func deriveKey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// encrypt returns value encrypted and marked with encryptedPrefix. Empty and
+// already encrypted values are returned unchanged.
+func (c *fieldCipher) encrypt(value string, deterministic bool) string {
+	if value == "" || strings.HasPrefix(value, encryptedPrefix) {
+		return value
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if deterministic {
+		mac := hmac.New(sha256.New, c.nonceKey)
+		mac.Write([]byte(value))
+		copy(nonce, mac.Sum(nil))
+	} else {
+		rand.Read(nonce)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedPrefix + base64.RawStdEncoding.EncodeToString(sealed)
+}
+
+// decrypt reverses encrypt. Plaintext values are returned unchanged.
+func (c *fieldCipher) decrypt(value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		return value, nil
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// encryptEmail returns email as it is stored.
+func encryptEmail(email string) string {
+	if fieldEncryption == nil {
+		return email
+	}
+	return fieldEncryption.encrypt(email, true)
+}
+
+// encryptPhone returns phone as it is stored.
+func encryptPhone(phone string) string {
+	if fieldEncryption == nil {
+		return phone
+	}
+	return fieldEncryption.encrypt(phone, false)
+}
+
+// encryptPhoneDigits returns the normalized phone as it is stored in phoneDigits.
+func encryptPhoneDigits(digits string) string {
+	if fieldEncryption == nil {
+		return digits
+	}
+	return fieldEncryption.encrypt(digits, true)
+}
+
+// storedPhoneDigits returns the values the normalized phone digits may be
+// stored as, like storedEmails.
+func storedPhoneDigits(digits string) bson.A {
+	if stored := encryptPhoneDigits(digits); stored != digits {
+		return bson.A{digits, stored}
+	}
+	return bson.A{digits}
+}
+
+// storedEmails returns the values email may be stored as: its plaintext, and
+// its ciphertext when encryption is on, for documents not yet migrated.
+func storedEmails(email string) bson.A {
+	if stored := encryptEmail(email); stored != email {
+		return bson.A{email, stored}
+	}
+	return bson.A{email}
+}
+
+// encryptEmployee encrypts the contact fields of a document about to be
+// written.
+func encryptEmployee(employee *models.Employee) {
+	if fieldEncryption == nil {
+		return
+	}
+	employee.Email = encryptEmail(employee.Email)
+	employee.Phone = encryptPhone(employee.Phone)
+	employee.PhoneDigits = encryptPhoneDigits(employee.PhoneDigits)
+}
+
+// decryptValue decrypts one stored value. A value that cannot be decrypted,
+// e.g. after the key changed, is logged and returned as stored.
+func decryptValue(value string) string {
+	if fieldEncryption == nil {
+		return value
+	}
+	plaintext, err := fieldEncryption.decrypt(value)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return value
+	}
+	return plaintext
+}
+
+// decryptEmployee decrypts the contact fields of an employee read from the database.
+func decryptEmployee(employee *models.Employee) {
+	employee.Email = decryptValue(employee.Email)
+	employee.Phone = decryptValue(employee.Phone)
+}
+
+// decryptEmployees decrypts every employee of a list in place.
+func decryptEmployees(employees []models.Employee) {
+	for i := range employees {
+		decryptEmployee(&employees[i])
+	}
+}
+
+// isEncrypted reports whether a stored value is an encrypted string.
+func isEncrypted(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && strings.HasPrefix(s, encryptedPrefix)
+}
+
+// decryptAuditChanges decrypts the encrypted fields of audit entries, which
+// store values as they were written. Changes that only re-encrypted the same
+// value, recorded before diffDocuments compared plaintexts, are dropped.
+func decryptAuditChanges(entries []auditEntry) {
+	for i := range entries {
+		changes := entries[i].Changes[:0]
+		for _, change := range entries[i].Changes {
+			if contains(encryptedFields, change.Field) {
+				if isEncrypted(change.OldValue) && isEncrypted(change.NewValue) &&
+					samePlaintext(change.Field, change.OldValue, change.NewValue) {
+					continue
+				}
+				if value, ok := change.OldValue.(string); ok {
+					change.OldValue = decryptValue(value)
+				}
+				if value, ok := change.NewValue.(string); ok {
+					change.NewValue = decryptValue(value)
+				}
+			}
+			changes = append(changes, change)
+		}
+		entries[i].Changes = changes
+	}
+}
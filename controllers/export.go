@@ -0,0 +1,472 @@
+package controllers
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// csvFlushEvery is how many rows an export writes between flushes to the client.
+const csvFlushEvery = 500
+
+// csvColumns maps each exportable column to its value extractor.
+var csvColumns = map[string]func(models.Employee) string{
+	"id":              func(e models.Employee) string { return e.ID.Hex() },
+	"name":            func(e models.Employee) string { return e.Name },
+	"email":           func(e models.Employee) string { return e.Email },
+	"phone":           func(e models.Employee) string { return e.Phone },
+	"department":      func(e models.Employee) string { return e.Department },
+	"status":          func(e models.Employee) string { return e.Status },
+	"salary":          func(e models.Employee) string { return formatFloatPtr(e.Salary) },
+	"managerId":       func(e models.Employee) string { return hexOrEmpty(e.ManagerID) },
+	"joinedAt":        func(e models.Employee) string { return formatTimePtr(e.JoinedAt) },
+	"terminationDate": func(e models.Employee) string { return formatTimePtr(e.TerminationDate) },
+	"createdAt":       func(e models.Employee) string { return formatTime(e.CreatedAt) },
+	"createdBy":       func(e models.Employee) string { return e.CreatedBy },
+	"updatedAt":       func(e models.Employee) string { return formatTime(e.UpdatedAt) },
+	"lastSeenAt":      func(e models.Employee) string { return formatTimePtr(e.LastSeenAt) },
+}
+
+// defaultCSVColumns is the column order used when ?columns= is not supplied.
+var defaultCSVColumns = []string{"id", "name", "email", "phone", "department", "status"}
+
+// csvDelimiters allowlists the ?delimiter= values, by name or as the character
+// itself (URL-encoded, e.g. %3B for a semicolon).
+var csvDelimiters = map[string]rune{
+	"comma":     ',',
+	",":         ',',
+	"semicolon": ';',
+	";":         ';',
+	"tab":       '\t',
+	"\t":        '\t',
+}
+
+// utf8BOM lets Excel detect that a CSV file is UTF-8.
+const utf8BOM = "\uFEFF"
+
+// exportWriter is the body of a streamed export, gzip-compressed when the
+// client asked for it. Flush pushes everything written so far to the client.
+type exportWriter struct {
+	io.Writer
+	gz      *gzip.Writer
+	flusher http.Flusher
+}
+
+// startExport writes the headers of an export response and returns its body.
+// The body is compressed when the request sends Accept-Encoding: gzip or
+// ?gzip=true, in which case the attachment is named filename + ".gz".
+func startExport(w http.ResponseWriter, r *http.Request, contentType, filename string) *exportWriter {
+	out := &exportWriter{Writer: w}
+	out.flusher, _ = w.(http.Flusher)
+	clearWriteDeadline(w)
+
+	w.Header().Add("Vary", "Accept-Encoding")
+	w.Header().Set("Content-Type", contentType)
+	if wantsGzip(r) {
+		out.gz = gzip.NewWriter(w)
+		out.Writer = out.gz
+		filename += ".gz"
+		w.Header().Set("Content-Encoding", "gzip")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.WriteHeader(http.StatusOK)
+	return out
+}
+
+// Flush sends the compressed output produced so far, then flushes the response.
+func (e *exportWriter) Flush() {
+	if e.gz != nil {
+		e.gz.Flush()
+	}
+	if e.flusher != nil {
+		e.flusher.Flush()
+	}
+}
+
+// Close writes the gzip trailer; it must be called once the export is done.
+func (e *exportWriter) Close() {
+	if e.gz != nil {
+		if err := e.gz.Close(); err != nil {
+			log.Printf("Warning: error finishing gzip export: %v", err)
+		}
+	}
+}
+
+// wantsGzip reports whether the export should be gzip-compressed.
+func wantsGzip(r *http.Request) bool {
+	if gz, err := strconv.ParseBool(r.URL.Query().Get("gzip")); err == nil {
+		return gz
+	}
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = parsed
+			}
+		}
+		return q > 0
+	}
+	return false
+}
+
+// ExportEmployeesCSV - HTTP handler to export employees as CSV
+//
+// Rows are streamed straight from the Mongo cursor to the response and flushed
+// every csvFlushEvery rows, so memory use is constant regardless of collection
+// size and the response is sent chunked. Supports the list filters
+// (department, status, search) and ?columns=name,email,... to pick columns.
+// ?delimiter= picks the separator (comma, the default, semicolon or tab) and
+// ?bom=true starts the file with a UTF-8 byte order mark, which Excel needs to
+// read non-ASCII names correctly. See startExport for gzip compression.
+func ExportEmployeesCSV(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter, err := buildEmployeeFilter(query, canViewPII(r))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts, err := parseCSVOptions(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := checkDatabase(); err != nil {
+		writeServerError(w, "Failed to export employees", err)
+		return
+	}
+	cur, err := listCollection.Find(r.Context(), filter)
+	if err != nil {
+		writeServerError(w, "Failed to export employees", err)
+		return
+	}
+	defer closeCursor(r.Context(), cur)
+
+	out := startExport(w, r, "text/csv; charset=utf-8", "employees.csv")
+	defer out.Close()
+	writer := newCSVWriter(out, opts)
+
+	maskPII := !canViewPII(r)
+	row := make([]string, len(opts.columns))
+	rows := 0
+	for cur.Next(r.Context()) {
+		var employee models.Employee
+		if err := cur.Decode(&employee); err != nil {
+			// Headers are already sent; all we can do is stop and log.
+			log.Printf("CSV export aborted: error decoding employee: %v", err)
+			break
+		}
+		decryptEmployee(&employee)
+		if maskPII {
+			employee = maskEmployee(employee)
+		}
+		writeCSVRow(writer, opts.columns, row, employee)
+
+		rows++
+		if rows%csvFlushEvery == 0 {
+			writer.Flush()
+			out.Flush()
+		}
+	}
+	if err := cur.Err(); err != nil {
+		log.Printf("CSV export aborted: cursor error: %v", err)
+	}
+
+	writer.Flush()
+	fmt.Printf("Exported %d employees as CSV\n", rows)
+}
+
+// ExportEmployeesJSONL - HTTP handler to export employees as JSON Lines
+//
+// One employee object per line, streamed from the cursor with the same
+// filters as the list endpoint and constant memory use. See startExport for
+// gzip compression.
+func ExportEmployeesJSONL(w http.ResponseWriter, r *http.Request) {
+	filter, err := buildEmployeeFilter(r.URL.Query(), canViewPII(r))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := checkDatabase(); err != nil {
+		writeServerError(w, "Failed to export employees", err)
+		return
+	}
+	cur, err := listCollection.Find(r.Context(), filter)
+	if err != nil {
+		writeServerError(w, "Failed to export employees", err)
+		return
+	}
+	defer closeCursor(r.Context(), cur)
+
+	out := startExport(w, r, "application/x-ndjson", "employees.jsonl")
+	defer out.Close()
+	encoder := json.NewEncoder(out)
+
+	maskPII := !canViewPII(r)
+	rows := 0
+	for cur.Next(r.Context()) {
+		var employee models.Employee
+		if err := cur.Decode(&employee); err != nil {
+			log.Printf("JSONL export aborted: error decoding employee: %v", err)
+			break
+		}
+		decryptEmployee(&employee)
+		if maskPII {
+			employee = maskEmployee(employee)
+		}
+		if err := encoder.Encode(employee); err != nil {
+			log.Printf("JSONL export aborted: %v", err)
+			break
+		}
+
+		rows++
+		if rows%csvFlushEvery == 0 {
+			out.Flush()
+		}
+	}
+	if err := cur.Err(); err != nil {
+		log.Printf("JSONL export aborted: cursor error: %v", err)
+	}
+
+	fmt.Printf("Exported %d employees as JSONL\n", rows)
+}
+
+// ExportDepartmentsZIP - HTTP handler to export employees as a ZIP of one CSV per department
+//
+// Each department gets a file named after it, e.g. Engineering.csv, with the
+// characters unsafe in file names replaced by _ and employees without a
+// department in no-department.csv. The archive is streamed like
+// ExportEmployeesCSV, one department at a time, and takes the same filters
+// and ?columns=, ?delimiter= and ?bom= parameters. It is not gzip-compressed:
+// the entries are already deflated.
+func ExportDepartmentsZIP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter, err := buildEmployeeFilter(query, canViewPII(r))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts, err := parseCSVOptions(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := checkDatabase(); err != nil {
+		writeServerError(w, "Failed to export employees", err)
+		return
+	}
+	sort := bson.D{{Key: "department", Value: 1}, {Key: "_id", Value: 1}}
+	cur, err := listCollection.Find(r.Context(), filter, options.Find().SetSort(sort))
+	if err != nil {
+		writeServerError(w, "Failed to export employees", err)
+		return
+	}
+	defer closeCursor(r.Context(), cur)
+
+	clearWriteDeadline(w)
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="employees-by-department.zip"`)
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	archive := zip.NewWriter(w)
+
+	maskPII := !canViewPII(r)
+	row := make([]string, len(opts.columns))
+	names := map[string]bool{}
+	var writer *csv.Writer
+	department := ""
+	rows := 0
+	for cur.Next(r.Context()) {
+		var employee models.Employee
+		if err := cur.Decode(&employee); err != nil {
+			log.Printf("ZIP export aborted: error decoding employee: %v", err)
+			break
+		}
+		decryptEmployee(&employee)
+
+		if writer == nil || employee.Department != department {
+			if writer != nil {
+				writer.Flush()
+			}
+			department = employee.Department
+			entry, err := archive.Create(departmentFileName(department, names))
+			if err != nil {
+				log.Printf("ZIP export aborted: %v", err)
+				break
+			}
+			writer = newCSVWriter(entry, opts)
+		}
+
+		if maskPII {
+			employee = maskEmployee(employee)
+		}
+		writeCSVRow(writer, opts.columns, row, employee)
+
+		rows++
+		if rows%csvFlushEvery == 0 {
+			writer.Flush()
+			archive.Flush()
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+	if err := cur.Err(); err != nil {
+		log.Printf("ZIP export aborted: cursor error: %v", err)
+	}
+
+	if writer != nil {
+		writer.Flush()
+	}
+	if err := archive.Close(); err != nil {
+		log.Printf("Warning: error finishing ZIP export: %v", err)
+	}
+	fmt.Printf("Exported %d employees in %d department files as ZIP\n", rows, len(names))
+}
+
+// departmentFileName returns the name of the CSV file of department in a ZIP
+// export, made unique among the names already taken by adding -2, -3, ...
+func departmentFileName(department string, taken map[string]bool) string {
+	base := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == ' ', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(department))
+	if base == "" {
+		base = "no-department"
+	}
+	name := base + ".csv"
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s-%d.csv", base, i)
+	}
+	taken[name] = true
+	return name
+}
+
+// csvOptions are the format parameters of a CSV export.
+type csvOptions struct {
+	columns   []string
+	delimiter rune
+	bom       bool
+}
+
+// parseCSVOptions reads ?columns=, ?delimiter= and ?bom=.
+func parseCSVOptions(query url.Values) (csvOptions, error) {
+	var opts csvOptions
+	var err error
+	if opts.columns, err = parseCSVColumns(query.Get("columns")); err != nil {
+		return opts, err
+	}
+	if opts.delimiter, err = parseCSVDelimiter(query.Get("delimiter")); err != nil {
+		return opts, err
+	}
+	if raw := query.Get("bom"); raw != "" {
+		if opts.bom, err = strconv.ParseBool(raw); err != nil {
+			return opts, errors.New("bom must be true or false")
+		}
+	}
+	return opts, nil
+}
+
+// newCSVWriter starts a CSV file on out: the byte order mark when asked for,
+// then the header row.
+func newCSVWriter(out io.Writer, opts csvOptions) *csv.Writer {
+	if opts.bom {
+		io.WriteString(out, utf8BOM)
+	}
+	writer := csv.NewWriter(out)
+	writer.Comma = opts.delimiter
+	writer.Write(opts.columns)
+	return writer
+}
+
+// writeCSVRow writes the columns of employee, using row as scratch space.
+func writeCSVRow(writer *csv.Writer, columns []string, row []string, employee models.Employee) {
+	for i, column := range columns {
+		row[i] = csvColumns[column](employee)
+	}
+	writer.Write(row)
+}
+
+// parseCSVColumns validates a comma-separated column list against csvColumns.
+func parseCSVColumns(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return defaultCSVColumns, nil
+	}
+
+	var columns []string
+	for _, column := range strings.Split(raw, ",") {
+		column = strings.TrimSpace(column)
+		if _, ok := csvColumns[column]; !ok {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// parseCSVDelimiter resolves ?delimiter= against csvDelimiters; empty means comma.
+func parseCSVDelimiter(raw string) (rune, error) {
+	if raw == "" {
+		return ',', nil
+	}
+	delimiter, ok := csvDelimiters[strings.ToLower(raw)]
+	if !ok {
+		return 0, fmt.Errorf("delimiter must be comma, semicolon or tab")
+	}
+	return delimiter, nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return formatTime(*t)
+}
+
+func hexOrEmpty(id *bson.ObjectID) string {
+	if id == nil {
+		return ""
+	}
+	return id.Hex()
+}
+
+func formatFloatPtr(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// knownFeatures are the optional groups of endpoints that DISABLED_FEATURES
+// can turn off.
+var knownFeatures = map[string]string{
+	"admin":     "/api/admin/* and /api/employees/validate-all",
+	"bulk":      "bulk-update-department, bulk-tag, bulk-offboard, batch-delete and batch",
+	"export":    "export.csv, export.jsonl, export/by-department.zip and /api/org-chart.dot",
+	"import":    "NDJSON import on POST /api/employees",
+	"metrics":   "/metrics",
+	"stats":     "salary-stats, status-stats and top-departments",
+	"stream":    "the /api/employees/stream Server-Sent Events",
+	"websocket": "the /ws WebSocket feed",
+}
+
+// EnabledFeatures returns, for each known feature, whether its endpoints are
+// served. Every feature is on unless listed in DISABLED_FEATURES, a
+// comma-separated list of names; unknown names are ignored with a warning.
+// The variable is read once.
+var EnabledFeatures = sync.OnceValue(func() map[string]bool {
+	enabled := map[string]bool{}
+	for name := range knownFeatures {
+		enabled[name] = true
+	}
+	for _, name := range strings.Split(os.Getenv("DISABLED_FEATURES"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := knownFeatures[name]; !ok {
+			log.Printf("Warning: ignoring unknown DISABLED_FEATURES entry %q", name)
+			continue
+		}
+		enabled[name] = false
+	}
+	return enabled
+})
+
+// enabledFeatureNames lists the enabled features, sorted, for the startup summary.
+func enabledFeatureNames() string {
+	var names []string
+	for name, enabled := range EnabledFeatures() {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,260 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// validStatuses are the accepted values of the status filter.
+var validStatuses = map[string]bool{
+	models.StatusActive:     true,
+	models.StatusOnLeave:    true,
+	models.StatusTerminated: true,
+}
+
+// errPhoneFilterEncrypted rejects ?phone= while ENCRYPTION_KEY is set: the
+// suffix match runs on phoneDigits, which is then stored encrypted.
+var errPhoneFilterEncrypted = errors.New("phone cannot be filtered while phone numbers are stored encrypted; use /employees/by-phone with the complete number")
+
+// statusAll is the ?status= value that lists employees of every status.
+const statusAll = "all"
+
+// defaultToActive restricts filter to active employees unless the query has
+// a status filter, ?status=all included. Employees stored without a status
+// predate it and count as active.
+func defaultToActive(query url.Values, filter bson.M) {
+	if strings.TrimSpace(query.Get("status")) == "" {
+		filter["status"] = bson.M{"$in": bson.A{models.StatusActive, nil}}
+	}
+}
+
+// buildEmployeeFilter translates list query parameters into a Mongo filter.
+// All conditions are combined with AND and soft-deleted employees are excluded.
+// Unless allowPII is set, conditions on email and phone are left out, as
+// callers who only see them masked could otherwise confirm them by probing.
+//
+//   - department: exact department name
+//   - status: one of active, on_leave, terminated, or all for no restriction
+//   - search: case-insensitive substring match on name, email or department;
+//     whitespace is normalized (see normalizeSearchQuery) and names also
+//     match regardless of accents (see foldSearchText). Encrypted emails only
+//     match the complete address
+//   - tag, tags: employees with the given tags; repeat the parameter or
+//     separate tags with commas (?tags=remote,mentor). tagMode=any (the
+//     default) matches employees with at least one of them, tagMode=all
+//     only those with every one
+//   - hasManager: true for employees with a manager, false for those without
+//   - phone: digits the phone number ends with, ignoring formatting, so
+//     "1234" or "123-1234" both find (555) 123-1234. Rejected while phones
+//     are encrypted; see errPhoneFilterEncrypted
+//   - createdBy: exact name of the caller who created the employee; employees
+//     created before it was recorded have none and never match
+//   - inactiveSince: an RFC 3339 timestamp or a YYYY-MM-DD date; employees
+//     not seen (see TouchEmployee) since then, including those never seen
+//   - updatedAfter, updatedBefore: an RFC 3339 timestamp or a YYYY-MM-DD date
+//     (midnight UTC); updatedAfter is inclusive and updatedBefore exclusive,
+//     so updatedAfter=2025-01-01&updatedBefore=2025-04-01 is Q1 2025
+func buildEmployeeFilter(query url.Values, allowPII bool) (bson.M, error) {
+	filter := bson.M{}
+
+	if department := strings.TrimSpace(query.Get("department")); department != "" {
+		filter["department"] = department
+	}
+
+	if status := strings.TrimSpace(query.Get("status")); status != "" && status != statusAll {
+		if !validStatuses[status] {
+			return nil, fmt.Errorf("invalid status %q: must be one of active, on_leave, terminated, all", status)
+		}
+		filter["status"] = status
+	}
+
+	if tags := queryTags(append(query["tag"], query["tags"]...)); len(tags) > 0 {
+		switch mode := strings.TrimSpace(query.Get("tagMode")); mode {
+		case "", "any":
+			filter["tags"] = bson.M{"$in": tags}
+		case "all":
+			filter["tags"] = bson.M{"$all": tags}
+		default:
+			return nil, fmt.Errorf("invalid tagMode %q: must be any or all", mode)
+		}
+	}
+
+	if raw := strings.TrimSpace(query.Get("hasManager")); raw != "" {
+		hasManager, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hasManager %q: must be true or false", raw)
+		}
+		// Equality with null matches both explicit nulls and missing fields.
+		if hasManager {
+			filter["managerId"] = bson.M{"$ne": nil}
+		} else {
+			filter["managerId"] = nil
+		}
+	}
+
+	if raw := strings.TrimSpace(query.Get("phone")); raw != "" && allowPII {
+		if fieldEncryption != nil {
+			return nil, errPhoneFilterEncrypted
+		}
+		digits := phoneDigits(raw)
+		if digits == "" {
+			return nil, fmt.Errorf("invalid phone %q: must contain at least one digit", raw)
+		}
+		// Anchored at the end so partial input behaves like "last N digits".
+		filter["phoneDigits"] = bson.Regex{Pattern: regexp.QuoteMeta(digits) + "$"}
+	}
+
+	if search := normalizeSearchQuery(query.Get("search")); search != "" {
+		filter["$or"] = searchConditions(search, allowPII)
+	}
+
+	if createdBy := strings.TrimSpace(query.Get("createdBy")); createdBy != "" {
+		filter["createdBy"] = createdBy
+	}
+
+	inactiveSince, err := parseFilterDate(query, "inactiveSince")
+	if err != nil {
+		return nil, err
+	}
+	if inactiveSince != nil {
+		// $not also matches documents without lastSeenAt.
+		filter["lastSeenAt"] = bson.M{"$not": bson.M{"$gte": *inactiveSince}}
+	}
+
+	updated, err := dateRangeCondition(query, "updatedAfter", "updatedBefore")
+	if err != nil {
+		return nil, err
+	}
+	if updated != nil {
+		filter["updatedAt"] = updated
+	}
+
+	return withNotDeleted(filter), nil
+}
+
+// dateRangeCondition builds a {$gte, $lt} condition from the afterParam and
+// beforeParam query parameters, or returns nil when neither is set.
+func dateRangeCondition(query url.Values, afterParam, beforeParam string) (bson.M, error) {
+	condition := bson.M{}
+
+	after, err := parseFilterDate(query, afterParam)
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		condition["$gte"] = *after
+	}
+
+	before, err := parseFilterDate(query, beforeParam)
+	if err != nil {
+		return nil, err
+	}
+	if before != nil {
+		condition["$lt"] = *before
+	}
+
+	if after != nil && before != nil && after.After(*before) {
+		return nil, fmt.Errorf("invalid date range: %s must not be later than %s", afterParam, beforeParam)
+	}
+	if len(condition) == 0 {
+		return nil, nil
+	}
+	return condition, nil
+}
+
+// parseFilterDate parses the named query parameter as an RFC 3339 timestamp
+// or a YYYY-MM-DD date. It returns nil when the parameter is absent.
+func parseFilterDate(query url.Values, name string) (*time.Time, error) {
+	raw := strings.TrimSpace(query.Get(name))
+	if raw == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid %s %q: must be an RFC 3339 timestamp or a YYYY-MM-DD date", name, raw)
+}
+
+// normalizeSearchQuery trims q and collapses runs of whitespace to one space,
+// so "  John   Doe " searches for "John Doe".
+func normalizeSearchQuery(q string) string {
+	return strings.Join(strings.Fields(q), " ")
+}
+
+// searchConditions returns $or clauses matching the normalized query q as a
+// literal, case-insensitive substring of any searchable field. Each space in q
+// matches any run of whitespace, so stored values with irregular spacing are
+// found too. Email is only searched when allowPII is set, and only for the
+// complete address, in any case, when it is encrypted (see fieldCipherFromEnv).
+func searchConditions(q string, allowPII bool) bson.A {
+	words := strings.Fields(q)
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	pattern := bson.Regex{Pattern: strings.Join(words, `\s+`), Options: "i"}
+	conditions := bson.A{
+		bson.M{"name": pattern},
+		bson.M{"nameSearch": foldedSearchPattern(q)},
+		bson.M{"department": pattern},
+	}
+	switch {
+	case !allowPII:
+	case fieldEncryption != nil:
+		// Encrypted emails cannot be matched by pattern, but their ciphertext
+		// is deterministic, so a complete address is still found.
+		conditions = append(conditions, bson.M{"email": bson.M{"$in": bson.A{encryptEmail(q), encryptEmail(strings.ToLower(q))}}})
+	default:
+		conditions = append(conditions, bson.M{"email": pattern})
+	}
+	return conditions
+}
+
+// phoneDigits strips everything but ASCII digits from a phone number. It is the
+// normalized form stored in phoneDigits and used for phone lookups.
+func phoneDigits(phone string) string {
+	var b strings.Builder
+	for _, c := range phone {
+		if c >= '0' && c <= '9' {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// stripMarks removes combining marks, such as the accent of a decomposed "é".
+var stripMarks = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// foldSearchText returns the accent-free, lowercased and space-normalized form
+// of s, so "  José  Núñez" becomes "jose nunez". It is stored in nameSearch for
+// diacritic-insensitive name search.
+func foldSearchText(s string) string {
+	folded, _, err := transform.String(stripMarks, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.ToLower(normalizeSearchQuery(folded))
+}
+
+// foldedSearchPattern matches the folded form of q as a substring of nameSearch.
+func foldedSearchPattern(q string) bson.Regex {
+	words := strings.Fields(foldSearchText(q))
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	return bson.Regex{Pattern: strings.Join(words, " ")}
+}
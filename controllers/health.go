@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+// startedAt is when the process started, for reporting uptime.
+var startedAt = time.Now()
+
+const healthPingTimeout = 2 * time.Second
+
+// Health statuses. Degraded means Mongo answered, but slower than
+// HEALTH_SLOW_PING_MS (default 500), so monitoring can alert before it fails.
+const (
+	healthOK       = "ok"
+	healthDegraded = "degraded"
+	healthDown     = "down"
+)
+
+type mongoHealth struct {
+	Status        string  `json:"status"`
+	PingLatencyMs float64 `json:"pingLatencyMs"`
+	Error         string  `json:"error,omitempty"`
+}
+
+type healthReport struct {
+	Status        string      `json:"status"`
+	UptimeSeconds int64       `json:"uptimeSeconds"`
+	Mongo         mongoHealth `json:"mongo"`
+}
+
+// Health - HTTP handler to report the service status along with Mongo
+// connectivity and ping latency. Responds 503 when Mongo is unreachable.
+func Health(w http.ResponseWriter, r *http.Request) {
+	mongo := pingMongo(r.Context())
+	report := healthReport{
+		Status:        mongo.Status,
+		UptimeSeconds: int64(time.Since(startedAt).Seconds()),
+		Mongo:         mongo,
+	}
+
+	status := http.StatusOK
+	if report.Status == healthDown {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, status, report)
+}
+
+// Liveness - HTTP handler for the liveness probe. It only reports that the
+// process is serving requests and never touches the database.
+func Liveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":        healthOK,
+		"uptimeSeconds": int64(time.Since(startedAt).Seconds()),
+	})
+}
+
+// pingMongo pings the primary and classifies the result by latency.
+func pingMongo(ctx context.Context) mongoHealth {
+	if !dbReady.Load() {
+		return mongoHealth{Status: healthDown, Error: "not connected"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthPingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := mongoClient.Ping(ctx, readpref.Primary())
+	latency := time.Since(start)
+
+	health := mongoHealth{
+		Status:        healthOK,
+		PingLatencyMs: float64(latency.Microseconds()) / 1000,
+	}
+	if err != nil {
+		health.Status = healthDown
+		health.Error = err.Error()
+		return health
+	}
+	if latency > time.Duration(envInt("HEALTH_SLOW_PING_MS", 500))*time.Millisecond {
+		health.Status = healthDegraded
+	}
+	return health
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// GetEmployeeHistory - HTTP handler to get the change history of one employee
+//
+// Returns audit entries oldest first, each with the actor, timestamp and the
+// field-level changes it made, paginated with ?limit= and ?cursor=. History
+// remains available after the employee has been deleted. The nth entry, counting
+// from 1, produced version n of the record; see GetEmployeeDiff.
+func GetEmployeeHistory(w http.ResponseWriter, r *http.Request) {
+	id := employeeIDFromRequest(r)
+
+	page, _, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := bson.M{"employeeId": id}
+	if page.After != nil {
+		filter["_id"] = bson.M{"$gt": page.After.ID}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(page.Limit) + 1)
+	cur, err := auditCollection.Find(r.Context(), filter, opts)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to retrieve history: %v", err))
+		return
+	}
+	defer closeCursor(r.Context(), cur)
+
+	entries := []auditEntry{}
+	if err := cur.All(r.Context(), &entries); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to retrieve history: %v", err))
+		return
+	}
+	decryptAuditChanges(entries)
+
+	if len(entries) == 0 && page.After == nil {
+		if _, err := findDocument(r.Context(), id); errors.Is(err, errEmployeeNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+	}
+
+	info := pageInfo{Limit: page.Limit}
+	if len(entries) > page.Limit {
+		entries = entries[:page.Limit]
+		info.HasMore = true
+		info.NextCursor = encodeCursor(pageCursor{ID: entries[len(entries)-1].ID})
+		w.Header().Set("X-Next-Cursor", info.NextCursor)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":       entries,
+		"pagination": info,
+	})
+}
+
+// GetEmployeeDiff - HTTP handler to compare two recorded versions of an employee
+//
+// ?from= and ?to= are version numbers: version n is the record as left by the
+// nth entry of its history, counting from 1. The response lists, per field
+// changed in between, its value at from and at to; fields changed and then
+// changed back are left out. 404 means the employee has no such version.
+func GetEmployeeDiff(w http.ResponseWriter, r *http.Request) {
+	id := employeeIDFromRequest(r)
+
+	from, ok := parseVersionParam(w, r, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseVersionParam(w, r, "to")
+	if !ok {
+		return
+	}
+	if from > to {
+		writeError(w, http.StatusBadRequest, "Query parameter 'from' must not be greater than 'to'")
+		return
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(to))
+	cur, err := auditCollection.Find(r.Context(), bson.M{"employeeId": id}, opts)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to retrieve history: %v", err))
+		return
+	}
+	defer closeCursor(r.Context(), cur)
+
+	entries := []auditEntry{}
+	if err := cur.All(r.Context(), &entries); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to retrieve history: %v", err))
+		return
+	}
+	if len(entries) < to {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Employee %s has no version %d; it has %d", id.Hex(), to, len(entries)))
+		return
+	}
+	decryptAuditChanges(entries)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"employeeId": id.Hex(),
+		"from":       from,
+		"to":         to,
+		"changes":    diffVersions(entries[from:to]),
+	})
+}
+
+// parseVersionParam reads a required version number, 1 or greater. It writes
+// the error response and returns false when it is missing or invalid.
+func parseVersionParam(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Query parameter '%s' is required", name))
+		return 0, false
+	}
+	version, err := strconv.Atoi(raw)
+	if err != nil || version < 1 {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Query parameter '%s' must be a version number of 1 or greater", name))
+		return 0, false
+	}
+	return version, true
+}
+
+// diffVersions folds consecutive audit entries into one change per field,
+// from the old value of its first change to the new value of its last.
+func diffVersions(entries []auditEntry) []auditChange {
+	byField := map[string]*auditChange{}
+	for _, entry := range entries {
+		for _, change := range entry.Changes {
+			if folded, ok := byField[change.Field]; ok {
+				folded.NewValue = change.NewValue
+				continue
+			}
+			folded := change
+			byField[change.Field] = &folded
+		}
+	}
+
+	changes := []auditChange{}
+	for _, change := range byField {
+		if !reflect.DeepEqual(change.OldValue, change.NewValue) {
+			changes = append(changes, *change)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
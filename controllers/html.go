@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+)
+
+// employeeTableTemplate renders GetEmployeesHTML. html/template escapes every
+// value for its context, so names and emails cannot inject markup.
+var employeeTableTemplate = template.Must(template.New("employees").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Employees</title>
+<style>
+body { font-family: sans-serif; font-size: 12px; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #999; padding: 4px 6px; text-align: left; }
+th { background: #eee; }
+@media print { .nav { display: none; } }
+</style>
+</head>
+<body>
+<h1>Employees</h1>
+<p>{{.Count}} employees{{if .Truncated}}, of {{.Total}}; paginate with ?limit= to see them all{{end}}</p>
+<table>
+<thead><tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{if .NextURL}}<p class="nav"><a href="{{.NextURL}}">Next page</a></p>{{end}}
+</body>
+</html>
+`))
+
+// employeeTable is the data of employeeTableTemplate.
+type employeeTable struct {
+	Columns   []string
+	Rows      [][]string
+	Count     int
+	Total     int64
+	Truncated bool
+	NextURL   string
+}
+
+// GetEmployeesHTML - HTTP handler to render the employee list as a printable HTML table
+//
+// Takes the filters, ?includeDeleted=, ?sort= and pagination of
+// GetAllEmployees, including its default to active employees only, and
+// ?columns= of ExportEmployeesCSV to pick the columns. When paginated, the
+// page links to the next one.
+func GetEmployeesHTML(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter, err := buildEmployeeFilter(query, canViewPII(r))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defaultToActive(query, filter)
+	filter, ok := parseIncludeDeleted(w, r, filter)
+	if !ok {
+		return
+	}
+	sort, ok := parseSortParam(w, r)
+	if !ok {
+		return
+	}
+	columns, err := parseCSVColumns(query.Get("columns"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, paginated, err := parsePageParams(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	table := employeeTable{Columns: columns}
+	var employees []models.Employee
+	if paginated {
+		var info pageInfo
+		employees, info, err = findEmployeesPage(r.Context(), filter, page, sort)
+		if errors.Is(err, errInvalidCursor) || errors.Is(err, errCursorSortMismatch) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if info.NextCursor != "" {
+			next := url.Values{}
+			for key, values := range query {
+				next[key] = values
+			}
+			next.Set("cursor", info.NextCursor)
+			next.Set("limit", strconv.Itoa(info.Limit))
+			table.NextURL = "?" + next.Encode()
+		}
+	} else {
+		employees, table.Truncated, err = getAllEmployees(r.Context(), filter, sort)
+		if err == nil && table.Truncated {
+			table.Total, err = listCollection.CountDocuments(r.Context(), filter)
+		}
+	}
+	if err != nil {
+		writeServerError(w, "Failed to retrieve employees", err)
+		return
+	}
+
+	maskPII := !canViewPII(r)
+	for _, employee := range employees {
+		if maskPII {
+			employee = maskEmployee(employee)
+		}
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = csvColumns[column](employee)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	table.Count = len(employees)
+
+	var body bytes.Buffer
+	if err := employeeTableTemplate.Execute(&body, table); err != nil {
+		log.Printf("Error rendering employee table: %v", err)
+		writeError(w, http.StatusInternalServerError, "Failed to render employees")
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes())
+}
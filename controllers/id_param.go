@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// employeeIDKey is the context key of the parsed {id} path variable.
+type employeeIDKey struct{}
+
+// EmployeeIDParam is middleware that parses the {id} path variable of the
+// matched route, if it has one, into an ObjectID stored in the request
+// context. Malformed ids are answered with 400 before the handler runs, so
+// handlers read a valid id with employeeIDFromRequest.
+func EmployeeIDParam(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, ok := mux.Vars(r)["id"]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		id, err := bson.ObjectIDFromHex(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("%v: %v", errInvalidEmployeeID, err))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), employeeIDKey{}, id)))
+	})
+}
+
+// employeeIDFromRequest returns the id parsed by EmployeeIDParam. It is the
+// zero ObjectID on routes without {id}.
+func employeeIDFromRequest(r *http.Request) bson.ObjectID {
+	id, _ := r.Context().Value(employeeIDKey{}).(bson.ObjectID)
+	return id
+}
@@ -0,0 +1,339 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const maxImportBatchSize = 5000
+
+// importRowError describes a single NDJSON line that could not be imported.
+// Status is the HTTP status the row would have received from a single create,
+// e.g. 409 for a duplicate email, so clients can handle both paths alike.
+type importRowError struct {
+	Line   int    `json:"line"`
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error"`
+}
+
+// insertFailure is one employee of a batch that the database rejected.
+type insertFailure struct {
+	Index int
+	Err   error
+}
+
+// importProgress is streamed back to the client after every batch, and once
+// more with Done set when the input is exhausted.
+type importProgress struct {
+	Batch    int              `json:"batch,omitempty"`
+	Inserted int              `json:"inserted"`
+	Failed   int              `json:"failed"`
+	Errors   []importRowError `json:"errors,omitempty"`
+	Done     bool             `json:"done,omitempty"`
+}
+
+// importRowReport is the verdict on one NDJSON line of a validateOnly import.
+type importRowReport struct {
+	Line   int    `json:"line"`
+	Valid  bool   `json:"valid"`
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// importValidationSummary ends the report of a validateOnly import.
+type importValidationSummary struct {
+	Valid   int  `json:"valid"`
+	Invalid int  `json:"invalid"`
+	Done    bool `json:"done"`
+}
+
+// ImportEmployeesNDJSON - HTTP handler to stream-import employees from newline-delimited JSON
+//
+// Each line of the application/x-ndjson body is one Employee, checked by the
+// same rules as a single create (see prepareCreate) and reported with the
+// status that create would answer. Lines are inserted in batches of
+// IMPORT_BATCH_SIZE (default 500, overridable per request with ?batchSize=),
+// and one progress object per batch is streamed back as NDJSON, followed by a
+// final summary with "done": true.
+// Invalid rows are reported and skipped; malformed JSON stops the import. Rows
+// whose unique key (see UNIQUE_KEY) is already taken, including by a
+// concurrent request, are rejected by the unique index and reported with
+// status 409.
+//
+// With ?validateOnly=true nothing is inserted; see validateImportNDJSON.
+func ImportEmployeesNDJSON(w http.ResponseWriter, r *http.Request) {
+	clearDeadlines(w)
+	batchSize := envInt("IMPORT_BATCH_SIZE", 500)
+	if raw := r.URL.Query().Get("batchSize"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxImportBatchSize {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("batchSize must be between 1 and %d", maxImportBatchSize))
+			return
+		}
+		batchSize = n
+	}
+
+	validateOnly := false
+	if raw := r.URL.Query().Get("validateOnly"); raw != "" {
+		var err error
+		if validateOnly, err = strconv.ParseBool(raw); err != nil {
+			writeError(w, http.StatusBadRequest, "Query parameter 'validateOnly' must be true or false")
+			return
+		}
+	}
+	if validateOnly {
+		validateImportNDJSON(w, r, batchSize)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	emit := func(progress importProgress) {
+		encoder.Encode(progress)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	total := importProgress{Done: true}
+	batch := make([]models.Employee, 0, batchSize)
+	batchLines := make([]int, 0, batchSize)
+	current := importProgress{Batch: 1}
+
+	flush := func() {
+		if len(batch) > 0 {
+			inserted, failures, err := insertManyEmployees(r.Context(), batch)
+			current.Inserted += inserted
+			current.Failed += len(batch) - inserted
+			for _, failure := range failures {
+				rowErr := importRowError{Line: batchLines[failure.Index], Error: failure.Err.Error()}
+				if errors.Is(failure.Err, errDuplicateKey) {
+					rowErr.Status = http.StatusConflict
+					rowErr.Error = duplicateKeyMessage()
+				}
+				current.Errors = append(current.Errors, rowErr)
+			}
+			if err != nil {
+				current.Errors = append(current.Errors, importRowError{Status: http.StatusInternalServerError, Error: err.Error()})
+			}
+		}
+		if len(batch) == 0 && current.Failed == 0 {
+			return
+		}
+		total.Inserted += current.Inserted
+		total.Failed += current.Failed
+		emit(current)
+		batch = batch[:0]
+		batchLines = batchLines[:0]
+		current = importProgress{Batch: current.Batch + 1}
+	}
+
+	for line := 1; ; line++ {
+		var employee models.Employee
+		err := decoder.Decode(&employee)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			current.Failed++
+			current.Errors = append(current.Errors, importRowError{Line: line, Error: fmt.Sprintf("Invalid JSON, import stopped: %v", err)})
+			break
+		}
+
+		if status, msg := prepareCreate(r.Context(), &employee); status != 0 {
+			current.Failed++
+			current.Errors = append(current.Errors, importRowError{Line: line, Status: status, Error: msg})
+			continue
+		}
+
+		batch = append(batch, employee)
+		batchLines = append(batchLines, line)
+		if len(batch) == batchSize {
+			flush()
+		}
+	}
+	flush()
+
+	emit(total)
+	fmt.Printf("NDJSON import finished: %d inserted, %d failed\n", total.Inserted, total.Failed)
+}
+
+// insertManyEmployees inserts a batch of employees without stopping at the first
+// failure. It returns how many were inserted and the employees the database
+// rejected, by index into employees; duplicate keys are reported as
+// errDuplicateKey. The error is only set when the batch as a whole failed,
+// or when its audit entries could not be written under the STRICT policy.
+func insertManyEmployees(ctx context.Context, employees []models.Employee) (int, []insertFailure, error) {
+	if err := checkDatabase(); err != nil {
+		return 0, nil, err
+	}
+	docs := make([]models.Employee, len(employees))
+	for i, employee := range employees {
+		docs[i] = newEmployeeDocument(ctx, employee)
+		docs[i].ID = bson.NewObjectID()
+	}
+
+	failed := map[int]bool{}
+	var failures []insertFailure
+	_, err := collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		var bulkErr mongo.BulkWriteException
+		if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+			return 0, nil, fmt.Errorf("error inserting employees: %w", err)
+		}
+		for _, writeErr := range bulkErr.WriteErrors {
+			failed[writeErr.Index] = true
+			failure := insertFailure{Index: writeErr.Index, Err: fmt.Errorf("error inserting employee: %s", writeErr.Message)}
+			if mongo.IsDuplicateKeyError(writeErr) {
+				failure.Err = errDuplicateKey
+			}
+			failures = append(failures, failure)
+		}
+	}
+
+	entries := make([]auditEntry, 0, len(docs)-len(failed))
+	for i, doc := range docs {
+		if failed[i] {
+			continue
+		}
+		if after, err := toDocument(doc); err == nil {
+			entries = append(entries, newAuditEntry(ctx, auditCreate, doc.ID, nil, after))
+		}
+	}
+	if err := recordAuditMany(ctx, entries); err != nil {
+		return len(docs) - len(failed), failures, err
+	}
+
+	return len(docs) - len(failed), failures, nil
+}
+
+// validateImportNDJSON checks an NDJSON import without inserting anything. It
+// streams one importRowReport per line, in order, then an
+// importValidationSummary. Rows are validated as by the import, and their
+// unique keys are checked against the collection, soft-deleted employees
+// included, and against earlier rows of the same import; taken keys get status
+// 409.
+// The collection is queried once per batch of batchSize rows. As with
+// email-available, a row reported valid can still conflict with an employee
+// created before the real import runs.
+func validateImportNDJSON(w http.ResponseWriter, r *http.Request, batchSize int) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+
+	summary := importValidationSummary{Done: true}
+	reports := make([]importRowReport, 0, batchSize)
+	// pending indexes the reports still valid, by unique key, until checked
+	// against the collection.
+	pending := map[string]int{}
+	var pendingEmployees []models.Employee
+	firstLine := map[string]int{}
+
+	flush := func() {
+		if len(pending) > 0 {
+			taken, err := takenKeys(r.Context(), pendingEmployees)
+			for key, i := range pending {
+				switch {
+				case err != nil:
+					reports[i] = importRowReport{Line: reports[i].Line, Status: http.StatusInternalServerError, Error: err.Error()}
+				case taken[key]:
+					reports[i] = importRowReport{Line: reports[i].Line, Status: http.StatusConflict, Error: duplicateKeyMessage()}
+				}
+			}
+		}
+		for _, report := range reports {
+			if report.Valid {
+				summary.Valid++
+			} else {
+				summary.Invalid++
+			}
+			encoder.Encode(report)
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		reports = reports[:0]
+		pending = map[string]int{}
+		pendingEmployees = pendingEmployees[:0]
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	for line := 1; ; line++ {
+		var employee models.Employee
+		err := decoder.Decode(&employee)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			reports = append(reports, importRowReport{Line: line, Error: fmt.Sprintf("Invalid JSON, validation stopped: %v", err)})
+			break
+		}
+
+		report := importRowReport{Line: line, Valid: true}
+		if status, msg := prepareCreate(r.Context(), &employee); status != 0 {
+			report = importRowReport{Line: line, Status: status, Error: msg}
+		} else if first, ok := firstLine[uniqueKeyOf(employee)]; ok {
+			report = importRowReport{Line: line, Status: http.StatusConflict, Error: fmt.Sprintf("%s: same as line %d", duplicateKeyMessage(), first)}
+		} else {
+			key := uniqueKeyOf(employee)
+			firstLine[key] = line
+			pending[key] = len(reports)
+			pendingEmployees = append(pendingEmployees, employee)
+		}
+		reports = append(reports, report)
+
+		if len(reports) == batchSize {
+			flush()
+		}
+	}
+	flush()
+
+	encoder.Encode(summary)
+	fmt.Printf("NDJSON import validated: %d valid, %d invalid\n", summary.Valid, summary.Invalid)
+}
+
+// takenKeys returns which unique keys of employees, as by uniqueKeyOf, are
+// already stored.
+func takenKeys(ctx context.Context, employees []models.Employee) (map[string]bool, error) {
+	if err := checkDatabase(); err != nil {
+		return nil, err
+	}
+	conditions := bson.A{}
+	projection := bson.M{}
+	for _, employee := range employees {
+		conditions = append(conditions, uniqueKeyCondition(employee))
+	}
+	for _, field := range uniqueKey {
+		projection[field] = 1
+	}
+	cur, err := collection.Find(ctx, bson.M{"$or": conditions}, options.Find().SetProjection(projection))
+	if err != nil {
+		return nil, fmt.Errorf("error checking unique keys: %w", err)
+	}
+	defer closeCursor(ctx, cur)
+
+	var docs []models.Employee
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("error checking unique keys: %w", err)
+	}
+	taken := map[string]bool{}
+	for _, doc := range docs {
+		decryptEmployee(&doc)
+		taken[uniqueKeyOf(doc)] = true
+	}
+	return taken, nil
+}
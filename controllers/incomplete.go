@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// contactFields are the fields GetIncompleteEmployees requires to be filled in.
+var contactFields = []string{"phone", "email", "department"}
+
+// GetIncompleteEmployees - HTTP handler to list employees missing contact info
+//
+// An employee is incomplete when any of phone, email or department is absent,
+// null or empty. The result is always cursor-paginated, with ?limit= and
+// ?cursor= as on the list endpoint, and accepts the list filters and ?sort=.
+func GetIncompleteEmployees(w http.ResponseWriter, r *http.Request) {
+	filter, err := buildEmployeeFilter(r.URL.Query(), canViewPII(r))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// ?search= already uses $or, so the condition goes in $and.
+	filter["$and"] = bson.A{incompleteCondition()}
+
+	sort, ok := parseSortParam(w, r)
+	if !ok {
+		return
+	}
+	includeID, ok := parseIncludeIDParam(w, r)
+	if !ok {
+		return
+	}
+	page, _, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	getEmployeesPage(w, r, filter, page, sort, includeID)
+}
+
+// incompleteCondition matches documents where a contact field is missing or
+// empty. Equality with null also matches absent fields.
+func incompleteCondition() bson.M {
+	missing := bson.A{}
+	for _, field := range contactFields {
+		missing = append(missing, bson.M{field: bson.M{"$in": bson.A{nil, ""}}})
+	}
+	return bson.M{"$or": missing}
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// indexHint is one INDEX_HINTS entry: queries filtering on field use index.
+type indexHint struct {
+	field string
+	index string
+}
+
+// indexHints parses INDEX_HINTS, a comma-separated list of field:index pairs
+// such as "status:status_1,tags:tags_1", in order of preference. Malformed
+// pairs are ignored with a warning. The variable is read once.
+var indexHints = sync.OnceValue(func() []indexHint {
+	var hints []indexHint
+	for _, pair := range strings.Split(os.Getenv("INDEX_HINTS"), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		field, index, ok := strings.Cut(pair, ":")
+		field, index = strings.TrimSpace(field), strings.TrimSpace(index)
+		if !ok || field == "" || index == "" || strings.HasPrefix(field, "$") {
+			log.Printf("Warning: ignoring INDEX_HINTS pair %q: expected field:index", pair)
+			continue
+		}
+		hints = append(hints, indexHint{field: field, index: index})
+	}
+	return hints
+})
+
+// listIndexHint returns the name of the index the list query with filter
+// should use, per INDEX_HINTS: that of the first listed field the filter
+// has a condition on, or "" to leave the choice to the query planner. A hint
+// naming an index that does not exist makes the query fail.
+func listIndexHint(filter bson.M) string {
+	for _, hint := range indexHints() {
+		if _, ok := filter[hint.field]; ok {
+			return hint.index
+		}
+	}
+	return ""
+}
+
+// parseExplainParam reads ?explain=, which only admins may pass. It writes
+// the error response and returns false when the parameter is rejected.
+func parseExplainParam(w http.ResponseWriter, r *http.Request) (explain, ok bool) {
+	raw := r.URL.Query().Get("explain")
+	if raw == "" {
+		return false, true
+	}
+	explain, err := strconv.ParseBool(raw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Query parameter 'explain' must be true or false")
+		return false, false
+	}
+	if principal, ok := principalFromRequest(r); explain && (!ok || principal.Role != RoleAdmin) {
+		writeError(w, http.StatusForbidden, "explain requires the admin role")
+		return false, false
+	}
+	return explain, true
+}
+
+// explainListQuery runs the explain command, at executionStats verbosity, on
+// the find the list endpoint would run for filter, order and limit, with its
+// index hint, and returns the result as relaxed Extended JSON.
+func explainListQuery(ctx context.Context, filter bson.M, order bson.D, limit int) (json.RawMessage, error) {
+	if err := checkDatabase(); err != nil {
+		return nil, err
+	}
+	find := bson.D{{Key: "find", Value: listCollection.Name()}, {Key: "filter", Value: filter}}
+	if len(order) > 0 {
+		find = append(find, bson.E{Key: "sort", Value: order})
+	}
+	find = append(find, bson.E{Key: "limit", Value: limit})
+	if hint := listIndexHint(filter); hint != "" {
+		find = append(find, bson.E{Key: "hint", Value: hint})
+	}
+
+	command := bson.D{{Key: "explain", Value: find}, {Key: "verbosity", Value: "executionStats"}}
+	plan, err := listCollection.Database().RunCommand(ctx, command).Raw()
+	if err != nil {
+		return nil, fmt.Errorf("error explaining query: %w", err)
+	}
+	encoded, err := bson.MarshalExtJSON(plan, false, false)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding query plan: %w", err)
+	}
+	return encoded, nil
+}
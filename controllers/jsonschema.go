@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+)
+
+// jsonSchemaDialect is the JSON Schema version GetEmployeeJSONSchema follows.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// mediaTypeJSONSchema is the Content-Type of a JSON Schema document.
+const mediaTypeJSONSchema = "application/schema+json"
+
+// jsonSchema is the subset of JSON Schema the validate tags translate to.
+type jsonSchema struct {
+	Schema           string                 `json:"$schema,omitempty"`
+	Title            string                 `json:"title,omitempty"`
+	Description      string                 `json:"description,omitempty"`
+	Type             string                 `json:"type,omitempty"`
+	Format           string                 `json:"format,omitempty"`
+	Pattern          string                 `json:"pattern,omitempty"`
+	Enum             []string               `json:"enum,omitempty"`
+	Minimum          *float64               `json:"minimum,omitempty"`
+	Maximum          *float64               `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64               `json:"exclusiveMaximum,omitempty"`
+	MinLength        *int                   `json:"minLength,omitempty"`
+	MaxLength        *int                   `json:"maxLength,omitempty"`
+	MaxItems         *int                   `json:"maxItems,omitempty"`
+	ReadOnly         bool                   `json:"readOnly,omitempty"`
+	Items            *jsonSchema            `json:"items,omitempty"`
+	Properties       map[string]*jsonSchema `json:"properties,omitempty"`
+	Required         []string               `json:"required,omitempty"`
+}
+
+// GetEmployeeJSONSchema - HTTP handler to describe the employee payload as a JSON Schema
+//
+// The schema is derived, like GetEmployeeSchema, from the json and validate
+// tags of models.Employee, and from DEPARTMENTS and DEFAULT_DEPARTMENT.
+// Server-managed fields are marked readOnly. Rules JSON Schema cannot state,
+// such as the date range of joinedAt or the uniqueness of the email, are
+// given in descriptions or left to the server.
+func GetEmployeeJSONSchema(w http.ResponseWriter, r *http.Request) {
+	schema, _ := jsonSchemaOf(reflect.TypeOf(models.Employee{}), "", true)
+	schema.Schema = jsonSchemaDialect
+	schema.Title = "Employee"
+	if defaultDepartment() != "" {
+		// Creates without a department get DEFAULT_DEPARTMENT.
+		required := []string{}
+		for _, name := range schema.Required {
+			if name != "department" {
+				required = append(required, name)
+			}
+		}
+		schema.Required = required
+	}
+	writeCacheable(w, r, mediaTypeJSONSchema, schema, "")
+}
+
+// jsonSchemaOf returns the schema of a value of type t validated by tag, and
+// whether tag makes it required. Only the fields of the top level model are
+// server-managed.
+func jsonSchemaOf(t reflect.Type, tag string, topLevel bool) (*jsonSchema, bool) {
+	var field fieldSchema
+	describeType(&field, t)
+	applyValidateTag(&field, tag)
+
+	schema := &jsonSchema{
+		Type:      field.Type,
+		Format:    field.Format,
+		Pattern:   field.Pattern,
+		Enum:      field.Enum,
+		Minimum:   field.Minimum,
+		Maximum:   field.Maximum,
+		MinLength: field.MinLength,
+		MaxLength: field.MaxLength,
+		MaxItems:  field.MaxItems,
+	}
+	if field.ExclusiveMinimum {
+		schema.Minimum, schema.ExclusiveMinimum = nil, field.Minimum
+	}
+	if field.ExclusiveMaximum {
+		schema.Maximum, schema.ExclusiveMaximum = nil, field.Maximum
+	}
+	switch field.Format {
+	case "objectid":
+		schema.Format, schema.Pattern = "", "^[0-9a-fA-F]{24}$"
+	case "iso3166-1-alpha-2":
+		schema.Format, schema.Pattern = "", "^[A-Z]{2}$"
+		schema.Description = "An ISO 3166-1 alpha-2 country code"
+	}
+	if field.MinDate != "" {
+		schema.Description = "Not before " + field.MinDate + " and not in the future"
+	}
+
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case field.Type == "array":
+		_, elemTag, _ := strings.Cut(tag, "dive,")
+		schema.Items, _ = jsonSchemaOf(t.Elem(), elemTag, false)
+	case field.Type == "object":
+		schema.Properties = map[string]*jsonSchema{}
+		for i := 0; i < t.NumField(); i++ {
+			sub := t.Field(i)
+			name, _, _ := strings.Cut(sub.Tag.Get("json"), ",")
+			if name == "-" || !sub.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = sub.Name
+			}
+			property, required := jsonSchemaOf(sub.Type, sub.Tag.Get("validate"), false)
+			property.ReadOnly = topLevel && serverManagedFields[name]
+			schema.Properties[name] = property
+			if required {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+	}
+	return schema, field.Required
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/gorilla/mux"
+)
+
+// maintenanceMode is toggled at runtime; it starts from MAINTENANCE_MODE.
+var maintenanceMode atomic.Bool
+
+// MaintenanceMode returns middleware that rejects mutating requests
+// (POST, PUT, PATCH, DELETE) with 503 and a Retry-After header while
+// maintenance mode is on. Reads keep working, and routes in exempt (such as the
+// toggle itself) are never blocked. Retry-After is MAINTENANCE_RETRY_AFTER
+// seconds (default 300).
+func MaintenanceMode(exempt map[*mux.Route]bool) mux.MiddlewareFunc {
+	maintenanceMode.Store(envBool("MAINTENANCE_MODE", false))
+	retryAfter := strconv.Itoa(envInt("MAINTENANCE_RETRY_AFTER", 300))
+	if maintenanceMode.Load() {
+		log.Println("Maintenance mode is ON: write requests will be rejected")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if maintenanceMode.Load() && isWriteMethod(r.Method) && !exempt[mux.CurrentRoute(r)] {
+				w.Header().Set("Retry-After", retryAfter)
+				writeError(w, http.StatusServiceUnavailable, "Service is in maintenance mode; writes are temporarily disabled")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// GetMaintenanceMode - HTTP handler to report whether maintenance mode is on
+func GetMaintenanceMode(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]bool{"enabled": maintenanceMode.Load()})
+}
+
+// SetMaintenanceMode - HTTP handler to turn maintenance mode on or off
+func SetMaintenanceMode(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+	if req.Enabled == nil {
+		writeError(w, http.StatusUnprocessableEntity, "Field 'enabled' is required")
+		return
+	}
+
+	maintenanceMode.Store(*req.Enabled)
+	if principal, ok := principalFromRequest(r); ok {
+		log.Printf("Maintenance mode set to %t by %s", *req.Enabled, principal.Name)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]bool{"enabled": *req.Enabled})
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// errInvalidManager is returned when a ManagerID assignment breaks a reporting rule.
+var errInvalidManager = errors.New("invalid manager assignment")
+
+// checkManagerAssignment validates the ManagerID of employee, whose own ID is
+// selfID (nil on create). On updates the manager must exist and must not
+// report to the employee, directly or indirectly; see checkNoReportingCycle.
+// With ENFORCE_MANAGER_DEPARTMENT=true the manager must exist and belong to
+// the employee's department or to a parent of it. Departments form a
+// hierarchy by "/"-separated path, so "Engineering" is the parent of
+// "Engineering/Platform". With MAX_DIRECT_REPORTS set the manager must also
+// have room for another report; see checkDirectReports.
+func checkManagerAssignment(ctx context.Context, employee models.Employee, selfID *bson.ObjectID) error {
+	if employee.ManagerID == nil {
+		return nil
+	}
+	if selfID != nil && *employee.ManagerID == *selfID {
+		return fmt.Errorf("%w: an employee cannot be their own manager", errInvalidManager)
+	}
+	if selfID != nil {
+		if err := checkNoReportingCycle(ctx, *selfID, *employee.ManagerID); err != nil {
+			return err
+		}
+	}
+	if err := checkDirectReports(ctx, *employee.ManagerID, selfID); err != nil {
+		return err
+	}
+	if !envBool("ENFORCE_MANAGER_DEPARTMENT", false) {
+		return nil
+	}
+
+	var manager models.Employee
+	err := collection.FindOne(ctx, withNotDeleted(bson.M{"_id": *employee.ManagerID})).Decode(&manager)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return fmt.Errorf("%w: manager %s does not exist", errInvalidManager, employee.ManagerID.Hex())
+	}
+	if err != nil {
+		return fmt.Errorf("error finding manager: %w", err)
+	}
+
+	if !isSameOrParentDepartment(manager.Department, employee.Department) {
+		return fmt.Errorf("%w: manager department %q is not %q or a parent of it",
+			errInvalidManager, manager.Department, employee.Department)
+	}
+	return nil
+}
+
+// checkDirectReports enforces MAX_DIRECT_REPORTS, the span of control: a
+// manager who already has that many direct reports cannot be assigned another.
+// The employee being assigned (selfID) is not counted, so re-saving an
+// existing report is allowed. Unset or invalid disables the check.
+func checkDirectReports(ctx context.Context, managerID bson.ObjectID, selfID *bson.ObjectID) error {
+	limit := envInt("MAX_DIRECT_REPORTS", 0)
+	if limit == 0 {
+		return nil
+	}
+
+	filter := withNotDeleted(bson.M{"managerId": managerID})
+	if selfID != nil {
+		filter["_id"] = bson.M{"$ne": *selfID}
+	}
+	count, err := collection.CountDocuments(ctx, filter, options.Count().SetLimit(int64(limit)))
+	if err != nil {
+		return fmt.Errorf("error counting direct reports: %w", err)
+	}
+	if count >= int64(limit) {
+		return fmt.Errorf("%w: manager %s already has the maximum of %d direct reports",
+			errInvalidManager, managerID.Hex(), limit)
+	}
+	return nil
+}
+
+// checkNoReportingCycle checks that managerID exists and that employeeID is not
+// in its reporting chain, i.e. that the manager does not report to the employee.
+// The chain is walked upwards from the manager with $graphLookup.
+func checkNoReportingCycle(ctx context.Context, employeeID, managerID bson.ObjectID) error {
+	pipeline := bson.A{
+		bson.M{"$match": withNotDeleted(bson.M{"_id": managerID})},
+		bson.M{"$graphLookup": bson.M{
+			"from":                    collection.Name(),
+			"startWith":               "$managerId",
+			"connectFromField":        "managerId",
+			"connectToField":          "_id",
+			"as":                      "chain",
+			"restrictSearchWithMatch": notDeleted,
+		}},
+		bson.M{"$project": bson.M{"chain._id": 1}},
+	}
+
+	cur, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return fmt.Errorf("error walking reporting chain: %w", err)
+	}
+	defer closeCursor(ctx, cur)
+
+	var managers []struct {
+		Chain []struct {
+			ID bson.ObjectID `bson:"_id"`
+		} `bson:"chain"`
+	}
+	if err := cur.All(ctx, &managers); err != nil {
+		return fmt.Errorf("error walking reporting chain: %w", err)
+	}
+	if len(managers) == 0 {
+		return fmt.Errorf("%w: manager %s does not exist", errInvalidManager, managerID.Hex())
+	}
+	for _, link := range managers[0].Chain {
+		if link.ID == employeeID {
+			return fmt.Errorf("%w: manager %s reports to this employee", errInvalidManager, managerID.Hex())
+		}
+	}
+	return nil
+}
+
+// isSameOrParentDepartment reports whether parent equals child or is one of its
+// ancestors in the "/"-separated department path.
+func isSameOrParentDepartment(parent, child string) bool {
+	return parent == child || strings.HasPrefix(child, parent+"/")
+}
@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// errSameEmployee is returned when a merge names the same record twice.
+var errSameEmployee = errors.New("primary and secondary must be different employees")
+
+// mergeProtectedFields are never copied from the secondary record: its
+// identity, bookkeeping and lifecycle metadata stay its own.
+var mergeProtectedFields = map[string]bool{
+	"_id":            true,
+	"createdAt":      true,
+	"createdBy":      true,
+	"updatedAt":      true,
+	"deletedAt":      true,
+	"deletedBy":      true,
+	"deletionReason": true,
+	"anonymizedAt":   true,
+	"lastSeenAt":     true,
+	"mergedInto":     true,
+	"managerId":      true,
+}
+
+// MergeEmployees - HTTP handler to merge a duplicate employee into a primary record
+func MergeEmployees(w http.ResponseWriter, r *http.Request) {
+	var req models.MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	employee, err := mergeEmployees(r.Context(), req.Primary, req.Secondary)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidEmployeeID), errors.Is(err, errSameEmployee):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, errEmployeeNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeServerError(w, "Failed to merge employees", err)
+		}
+		return
+	}
+
+	if !canViewPII(r) {
+		employee = maskEmployee(employee)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Employees merged successfully",
+		"data":    employee,
+	})
+}
+
+// mergeEmployees folds the secondary employee into the primary inside a
+// transaction: fields set on the secondary but missing on the primary are
+// copied over, reports of the secondary are repointed to the primary, each
+// with an audit entry, and the secondary is soft-deleted with mergedInto and
+// deletedBy set. Transactions require MongoDB
+// to run as a replica set.
+func mergeEmployees(ctx context.Context, primaryHex, secondaryHex string) (models.Employee, error) {
+	var merged models.Employee
+
+	primaryID, err := bson.ObjectIDFromHex(primaryHex)
+	if err != nil {
+		return merged, fmt.Errorf("%w: %v", errInvalidEmployeeID, err)
+	}
+	secondaryID, err := bson.ObjectIDFromHex(secondaryHex)
+	if err != nil {
+		return merged, fmt.Errorf("%w: %v", errInvalidEmployeeID, err)
+	}
+	if primaryID == secondaryID {
+		return merged, errSameEmployee
+	}
+
+	session, err := startSession()
+	if err != nil {
+		return merged, err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = withTransaction(ctx, session, func(ctx context.Context) (interface{}, error) {
+		primary, err := findEmployeeDocument(ctx, primaryID)
+		if err != nil {
+			return nil, err
+		}
+		secondary, err := findEmployeeDocument(ctx, secondaryID)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now().UTC()
+		set := bson.M{"updatedAt": now}
+		for key, value := range secondary {
+			if _, exists := primary[key]; !exists && !mergeProtectedFields[key] {
+				set[key] = value
+			}
+		}
+		update := bson.M{"$set": set}
+
+		// A primary that reported to the secondary inherits the secondary's manager.
+		if managerID, ok := primary["managerId"].(bson.ObjectID); ok && managerID == secondaryID {
+			if next, ok := secondary["managerId"].(bson.ObjectID); ok && next != primaryID {
+				set["managerId"] = next
+			} else {
+				update["$unset"] = bson.M{"managerId": ""}
+			}
+		}
+
+		if _, err := collection.UpdateOne(ctx, bson.M{"_id": primaryID}, update); err != nil {
+			return nil, fmt.Errorf("error updating primary employee: %w", err)
+		}
+
+		reports, err := findAffected(ctx, bson.M{"managerId": secondaryID, "_id": bson.M{"$ne": primaryID}})
+		if err != nil {
+			return nil, err
+		}
+		reportIDs := affectedIDs(reports)
+		if len(reportIDs) > 0 {
+			_, err = collection.UpdateMany(ctx,
+				bson.M{"_id": bson.M{"$in": reportIDs}},
+				bson.M{"$set": bson.M{"managerId": primaryID, "updatedAt": now}},
+			)
+			if err != nil {
+				return nil, fmt.Errorf("error repointing reports: %w", err)
+			}
+		}
+
+		_, err = collection.UpdateOne(ctx, bson.M{"_id": secondaryID}, bson.M{
+			"$set": bson.M{"deletedAt": now, "deletedBy": actorFromContext(ctx), "mergedInto": primaryID, "updatedAt": now},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error soft-deleting secondary employee: %w", err)
+		}
+
+		primaryAfter, err := findDocument(ctx, primaryID)
+		if err != nil {
+			return nil, err
+		}
+		secondaryAfter, err := findDocument(ctx, secondaryID)
+		if err != nil {
+			return nil, err
+		}
+		if err := recordAudit(ctx, auditMerge, primaryID, primary, primaryAfter); err != nil {
+			return nil, err
+		}
+		if err := recordAudit(ctx, auditMerge, secondaryID, secondary, secondaryAfter); err != nil {
+			return nil, err
+		}
+		entries := make([]auditEntry, len(reportIDs))
+		for i, id := range reportIDs {
+			entries[i] = newAuditEntry(ctx, auditUpdate, id, bson.M{"managerId": secondaryID}, bson.M{"managerId": primaryID})
+		}
+		if err := recordAuditMany(ctx, entries); err != nil {
+			return nil, err
+		}
+
+		return nil, decodeDocument(primaryAfter, &merged)
+	})
+	// Reports of the secondary moved too, so drop everything rather than
+	// tracking each repointed id.
+	detailCache().purge()
+	if err != nil {
+		return merged, err
+	}
+
+	fmt.Printf("Merged employee %s into %s\n", secondaryHex, primaryHex)
+	return merged, nil
+}
+
+// findEmployeeDocument loads a non-deleted employee as a raw document so that
+// only the fields actually stored are visible.
+func findEmployeeDocument(ctx context.Context, id bson.ObjectID) (bson.M, error) {
+	if err := checkDatabase(); err != nil {
+		return nil, err
+	}
+	var doc bson.M
+	err := collection.FindOne(ctx, withNotDeleted(bson.M{"_id": id})).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("%w: %s", errEmployeeNotFound, id.Hex())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding employee: %w", err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// MoveEmployee - HTTP handler to move an employee under a new manager
+//
+// Only managerId (and updatedAt) is changed, after the checks of every manager
+// change; see checkManagerAssignment.
+func MoveEmployee(w http.ResponseWriter, r *http.Request) {
+	employeeID := employeeIDFromRequest(r)
+
+	var move models.ManagerMove
+	if err := json.NewDecoder(r.Body).Decode(&move); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+	if err := validate.Struct(move); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	current, err := getOneEmployee(r.Context(), employeeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errEmployeeNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeServerError(w, "Failed to retrieve employee", err)
+		}
+		return
+	}
+
+	employee, err := moveEmployee(r.Context(), current, *move.ManagerID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidManager):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, errEmployeeNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to move employee: %v", err))
+		}
+		return
+	}
+
+	if !canViewPII(r) {
+		employee = maskEmployee(employee)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Employee moved successfully",
+		"data":    employee,
+	})
+}
+
+// moveEmployee sets the manager of current to managerID after checking the
+// move, and returns the updated employee.
+func moveEmployee(ctx context.Context, current models.Employee, managerID bson.ObjectID) (models.Employee, error) {
+	var employee models.Employee
+
+	moved := current
+	moved.ManagerID = &managerID
+	if err := checkManagerAssignment(ctx, moved, &current.ID); err != nil {
+		return employee, err
+	}
+
+	update := bson.M{"$set": bson.M{"managerId": managerID, "updatedAt": time.Now().UTC()}}
+	var before bson.M
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	err := collection.FindOneAndUpdate(ctx, withNotDeleted(bson.M{"_id": current.ID}), update, opts).Decode(&before)
+	detailCache().invalidate(current.ID.Hex())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return employee, fmt.Errorf("%w: %s", errEmployeeNotFound, current.ID.Hex())
+	}
+	if err != nil {
+		return employee, fmt.Errorf("error moving employee: %w", err)
+	}
+	fmt.Printf("Moved employee with ID %s under manager %s\n", current.ID.Hex(), managerID.Hex())
+
+	after, err := findDocument(ctx, current.ID)
+	if err != nil {
+		return employee, err
+	}
+	if err := recordAudit(ctx, auditUpdate, current.ID, before, after); err != nil {
+		return employee, err
+	}
+	return employee, decodeDocument(after, &employee)
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultProduces is what routes without an explicit entry respond with.
+var defaultProduces = []string{"application/json"}
+
+// NegotiateContent returns middleware that responds 406 Not Acceptable when the
+// request's Accept header rules out every media type the matched route can
+// produce. produces lists those types per route; routes missing from it produce
+// JSON only. An absent Accept header accepts anything.
+func NegotiateContent(produces map[*mux.Route][]string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			types, ok := produces[mux.CurrentRoute(r)]
+			if !ok {
+				types = defaultProduces
+			}
+			if _, acceptable := negotiateMediaType(r.Header.Get("Accept"), types); !acceptable {
+				writeError(w, http.StatusNotAcceptable, "Not acceptable: this endpoint can produce "+strings.Join(types, ", "))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// negotiateMediaType picks the offered type the Accept header prefers most,
+// honoring q-values and wildcards. Ties go to the earlier offer. An empty
+// header selects the first offer.
+func negotiateMediaType(accept string, offers []string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], true
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := acceptQuality(accept, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// acceptQuality returns the q-value the Accept header assigns to mediaType,
+// using the most specific matching media range. Zero means not acceptable.
+func acceptQuality(accept, mediaType string) float64 {
+	wantType, wantSub, _ := strings.Cut(mediaType, "/")
+
+	quality, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		rangeType, rangeSub, _ := strings.Cut(mediaRange, "/")
+
+		var s int
+		switch {
+		case rangeType == wantType && rangeSub == wantSub:
+			s = 2
+		case rangeType == wantType && rangeSub == "*":
+			s = 1
+		case rangeType == "*" && rangeSub == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s <= specificity {
+			continue
+		}
+
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(raw, 64); err == nil {
+				q = parsed
+			}
+		}
+		quality, specificity = q, s
+	}
+	return quality
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	defaultNewHireDays = 30
+	maxNewHireDays     = 365
+)
+
+// GetNewHires - HTTP handler to list employees who joined in the last N days
+//
+// ?days= defaults to 30 and is capped at 365. Results are ordered by joinedAt,
+// most recent first, and accept the list filters (department, status, ...).
+func GetNewHires(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	days := defaultNewHireDays
+	if raw := query.Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxNewHireDays {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Query parameter 'days' must be between 1 and %d", maxNewHireDays))
+			return
+		}
+		days = n
+	}
+
+	filter, err := buildEmployeeFilter(query, canViewPII(r))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	filter["joinedAt"] = bson.M{"$gte": since}
+
+	sort := &sortSpec{Raw: "-joinedAt", Keys: bson.D{{Key: "joinedAt", Value: -1}}}
+	employees, truncated, err := getAllEmployees(r.Context(), filter, sort)
+	if err != nil {
+		writeServerError(w, "Failed to retrieve employees", err)
+		return
+	}
+	if truncated {
+		w.Header().Set("X-Result-Truncated", "true")
+	}
+	if !canViewPII(r) {
+		for i := range employees {
+			employees[i] = maskEmployee(employees[i])
+		}
+	}
+
+	writeJSON(w, http.StatusOK, employees)
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+)
+
+// contactTransforms are the normalizations that NORMALIZE_TRANSFORMS can turn
+// on, as a comma-separated list of names. None is applied by default, so
+// emails and phones are stored as sent.
+//
+//   - lowercase_email: lowercases the whole email address.
+//   - strip_plus_tag: drops a "+tag" suffix from the local part of the email,
+//     so "jane+hr@example.com" is stored as "jane@example.com".
+//   - e164_phone: formats the phone as E.164 ("+15550100"). Numbers starting
+//     with "+" or "00" keep their country code; other numbers get
+//     PHONE_DEFAULT_COUNTRY_CODE and are left untouched when it is unset.
+//     Numbers that cannot be valid E.164 (more than 15 digits) are left too.
+var contactTransforms = map[string]func(email, phone *string){
+	"lowercase_email": func(email, _ *string) { *email = strings.ToLower(*email) },
+	"strip_plus_tag":  func(email, _ *string) { *email = stripPlusTag(*email) },
+	"e164_phone":      func(_, phone *string) { *phone = formatE164(*phone) },
+}
+
+// contactTransformOrder applies the enabled transforms in a fixed order,
+// whatever their order in NORMALIZE_TRANSFORMS.
+var contactTransformOrder = []string{"lowercase_email", "strip_plus_tag", "e164_phone"}
+
+// enabledTransforms returns the transforms listed in NORMALIZE_TRANSFORMS.
+func enabledTransforms() map[string]bool {
+	enabled := map[string]bool{}
+	for _, name := range strings.Split(os.Getenv("NORMALIZE_TRANSFORMS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := contactTransforms[name]; !ok {
+			log.Printf("Warning: ignoring unknown NORMALIZE_TRANSFORMS entry %q", name)
+			continue
+		}
+		enabled[name] = true
+	}
+	return enabled
+}
+
+// normalizeContact applies the enabled transforms to an email and phone in
+// place. Empty values are left empty, so partial updates keep omitting them.
+func normalizeContact(email, phone *string) {
+	enabled := enabledTransforms()
+	if len(enabled) == 0 {
+		return
+	}
+	for _, name := range contactTransformOrder {
+		if !enabled[name] {
+			continue
+		}
+		e, p := *email, *phone
+		contactTransforms[name](&e, &p)
+		if *email != "" {
+			*email = e
+		}
+		if *phone != "" {
+			*phone = p
+		}
+	}
+}
+
+// normalizeEmployee applies the enabled transforms to the contact fields of employee.
+func normalizeEmployee(employee *models.Employee) {
+	normalizeContact(&employee.Email, &employee.Phone)
+}
+
+// stripPlusTag removes the "+tag" part of the local part of an email address.
+func stripPlusTag(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+	local, domain := email[:at], email[at:]
+	if plus := strings.Index(local, "+"); plus > 0 {
+		local = local[:plus]
+	}
+	return local + domain
+}
+
+// formatE164 formats phone as E.164, or returns it unchanged when it cannot.
+func formatE164(phone string) string {
+	trimmed := strings.TrimSpace(phone)
+	digits := phoneDigits(trimmed)
+	switch {
+	case strings.HasPrefix(trimmed, "+"):
+	case strings.HasPrefix(digits, "00"):
+		digits = digits[2:]
+	default:
+		countryCode := phoneDigits(os.Getenv("PHONE_DEFAULT_COUNTRY_CODE"))
+		if countryCode == "" {
+			return phone
+		}
+		digits = countryCode + strings.TrimLeft(digits, "0")
+	}
+	if digits == "" || digits[0] == '0' || len(digits) > 15 {
+		return phone
+	}
+	return "+" + digits
+}
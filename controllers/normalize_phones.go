@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// phoneChange is a phone number NormalizePhones reformats.
+type phoneChange struct {
+	ID   string `json:"id"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// unparsedPhone is a phone number NormalizePhones cannot format as E.164.
+type unparsedPhone struct {
+	ID    string `json:"id"`
+	Phone string `json:"phone"`
+}
+
+// NormalizePhones - HTTP handler to reformat every stored phone number as E.164
+//
+// Each non-deleted employee's phone goes through the e164_phone transform of
+// NORMALIZE_TRANSFORMS, whether or not it is enabled, so numbers without a
+// country code need PHONE_DEFAULT_COUNTRY_CODE. The response lists the
+// numbers that change, {id, from, to}, and those that cannot be formatted,
+// which are left as they are. With ?dryRun=true nothing is written; otherwise
+// the changes are applied in one bulk write and audited. A phone edited since
+// the scan is not overwritten.
+func NormalizePhones(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := parseDryRun(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := checkDatabase(); err != nil {
+		writeServerError(w, "Failed to scan employees", err)
+		return
+	}
+	filter := withNotDeleted(bson.M{"phone": bson.M{"$nin": bson.A{nil, ""}}})
+	opts := options.Find().SetProjection(bson.M{"phone": 1})
+	cur, err := listCollection.Find(r.Context(), filter, opts)
+	if err != nil {
+		writeServerError(w, "Failed to scan employees", err)
+		return
+	}
+	defer closeCursor(r.Context(), cur)
+
+	now := time.Now().UTC()
+	scanned := 0
+	changes := []phoneChange{}
+	unparsed := []unparsedPhone{}
+	var updates []mongo.WriteModel
+	var entries []auditEntry
+	for cur.Next(r.Context()) {
+		var doc struct {
+			ID    bson.ObjectID `bson:"_id"`
+			Phone string        `bson:"phone"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			writeServerError(w, "Failed to scan employees", err)
+			return
+		}
+		scanned++
+
+		phone := decryptValue(doc.Phone)
+		normalized := formatE164(phone)
+		if !isE164(normalized) {
+			unparsed = append(unparsed, unparsedPhone{ID: doc.ID.Hex(), Phone: phone})
+			continue
+		}
+		if normalized == phone {
+			continue
+		}
+		changes = append(changes, phoneChange{ID: doc.ID.Hex(), From: phone, To: normalized})
+
+		stored := encryptPhone(normalized)
+		updates = append(updates, mongo.NewUpdateOneModel().
+			SetFilter(withNotDeleted(bson.M{"_id": doc.ID, "phone": doc.Phone})).
+			SetUpdate(bson.M{"$set": bson.M{
+				"phone":       stored,
+				"phoneDigits": encryptPhoneDigits(phoneDigits(normalized)),
+				"updatedAt":   now,
+			}}))
+		entries = append(entries, newAuditEntry(r.Context(), auditUpdate, doc.ID,
+			bson.M{"phone": doc.Phone}, bson.M{"phone": stored}))
+	}
+	if err := cur.Err(); err != nil {
+		writeServerError(w, "Failed to scan employees", err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"dryRun":     dryRun,
+		"scanned":    scanned,
+		"normalized": changes,
+		"unparsed":   unparsed,
+	}
+	if dryRun || len(updates) == 0 {
+		writeJSON(w, http.StatusOK, response)
+		return
+	}
+
+	result, err := collection.BulkWrite(r.Context(), updates, options.BulkWrite().SetOrdered(false))
+	detailCache().purge()
+	if err != nil {
+		writeServerError(w, "Failed to normalize phones", err)
+		return
+	}
+	fmt.Printf("Normalized the phone numbers of %d employees\n", result.ModifiedCount)
+	if err := recordAuditMany(r.Context(), entries); err != nil {
+		writeServerError(w, "Failed to normalize phones", err)
+		return
+	}
+
+	response["modified"] = result.ModifiedCount
+	writeJSON(w, http.StatusOK, response)
+}
+
+// isE164 reports whether phone is "+" followed by 1 to 15 digits, the first
+// not 0, as formatE164 produces.
+func isE164(phone string) bool {
+	digits := phoneDigits(phone)
+	return len(phone) > 1 && phone[0] == '+' && phone[1:] == digits && len(digits) <= 15 && digits[0] != '0'
+}
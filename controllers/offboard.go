@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// offboardResult counts what a bulk offboard changed.
+type offboardResult struct {
+	Offboarded int      `json:"offboarded"`
+	Reassigned int      `json:"reassigned"`
+	NotFound   []string `json:"notFound"`
+}
+
+// BulkOffboardEmployees - HTTP handler to remove a whole team in one request
+//
+// The listed employees are soft-deleted as by BatchDeleteEmployees, and every
+// remaining employee who reported to one of them is left without a manager.
+// Both changes are written in one transaction, which requires MongoDB to run
+// as a replica set, with an audit entry per employee touched; see
+// withTransaction. The response
+// counts the offboarded employees and the reports whose manager was cleared;
+// ids that do not exist or are already deleted are listed in notFound.
+func BulkOffboardEmployees(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := parseDryRun(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req models.BulkOffboard
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+	if err := checkArraySizes(map[string]int{"ids": len(req.IDs)}); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+	ids, err := parseObjectIDs(req.IDs)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if dryRun {
+		writeDryRun(w, r, withNotDeleted(bson.M{"_id": bson.M{"$in": ids}}))
+		return
+	}
+
+	result, err := offboardEmployees(r.Context(), ids, strings.TrimSpace(req.Reason))
+	if err != nil {
+		writeServerError(w, "Failed to offboard employees", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":    "Employees offboarded successfully",
+		"offboarded": result.Offboarded,
+		"reassigned": result.Reassigned,
+		"notFound":   result.NotFound,
+	})
+}
+
+// offboardEmployees soft-deletes the employees in ids and unsets managerId on
+// their remaining reports, inside a transaction.
+func offboardEmployees(ctx context.Context, ids []bson.ObjectID, reason string) (offboardResult, error) {
+	var result offboardResult
+
+	session, err := startSession()
+	if err != nil {
+		return result, err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = withTransaction(ctx, session, func(ctx context.Context) (interface{}, error) {
+		// The transaction may be retried, so start from scratch each time.
+		result = offboardResult{NotFound: []string{}}
+
+		affected, err := findAffected(ctx, withNotDeleted(bson.M{"_id": bson.M{"$in": ids}}))
+		if err != nil {
+			return nil, err
+		}
+		offboardedIDs := affectedIDs(affected)
+
+		now := time.Now().UTC()
+		set := bson.M{"deletedAt": now, "deletedBy": actorFromContext(ctx), "updatedAt": now}
+		if reason != "" {
+			set["deletionReason"] = reason
+		}
+		if _, err := collection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": offboardedIDs}}, bson.M{"$set": set}); err != nil {
+			return nil, fmt.Errorf("error soft-deleting employees: %w", err)
+		}
+
+		reports, err := findAffected(ctx, withNotDeleted(bson.M{"managerId": bson.M{"$in": offboardedIDs}}), "managerId")
+		if err != nil {
+			return nil, err
+		}
+		reportIDs := affectedIDs(reports)
+		_, err = collection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": reportIDs}}, bson.M{
+			"$unset": bson.M{"managerId": ""},
+			"$set":   bson.M{"updatedAt": now},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error clearing managers: %w", err)
+		}
+
+		entries := make([]auditEntry, 0, len(offboardedIDs)+len(reports))
+		for _, id := range offboardedIDs {
+			entries = append(entries, newAuditEntry(ctx, auditDelete, id, bson.M{}, set))
+		}
+		for _, report := range reports {
+			id, _ := report["_id"].(bson.ObjectID)
+			entries = append(entries, newAuditEntry(ctx, auditUpdate, id, bson.M{"managerId": report["managerId"]}, bson.M{}))
+		}
+		if err := recordAuditMany(ctx, entries); err != nil {
+			return nil, err
+		}
+
+		offboarded := map[bson.ObjectID]bool{}
+		for _, id := range offboardedIDs {
+			offboarded[id] = true
+		}
+		for _, id := range ids {
+			if !offboarded[id] {
+				result.NotFound = append(result.NotFound, id.Hex())
+			}
+		}
+		result.Offboarded = len(offboardedIDs)
+		result.Reassigned = len(reportIDs)
+		return nil, nil
+	})
+	detailCache().purge()
+	if err != nil {
+		return result, err
+	}
+
+	fmt.Printf("Offboarded %d employees, cleared the manager of %d reports\n", result.Offboarded, result.Reassigned)
+	return result, nil
+}
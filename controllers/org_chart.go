@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// orgNode is one employee in the org chart.
+type orgNode struct {
+	ID        bson.ObjectID  `bson:"_id"`
+	Name      string         `bson:"name"`
+	ManagerID *bson.ObjectID `bson:"managerId"`
+}
+
+// GetOrgChartDOT - HTTP handler to export the reporting hierarchy as Graphviz DOT
+//
+// Every employee is a node labeled with their name, and each manager has an
+// edge to each of their reports. Employees without a manager, or whose
+// manager no longer exists, are the roots. Employees that no root leads to
+// are caught in (or below) a reporting cycle; they are still drawn, in red,
+// so the cycle is visible rather than hidden.
+func GetOrgChartDOT(w http.ResponseWriter, r *http.Request) {
+	opts := options.Find().
+		SetProjection(bson.M{"name": 1, "managerId": 1}).
+		SetSort(bson.D{{Key: "_id", Value: 1}})
+	cur, err := listCollection.Find(r.Context(), notDeleted, opts)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to build org chart: %v", err))
+		return
+	}
+	defer closeCursor(r.Context(), cur)
+
+	nodes := []orgNode{}
+	if err := cur.All(r.Context(), &nodes); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to build org chart: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="org-chart.dot"`)
+	out := bufio.NewWriter(w)
+	writeOrgChartDOT(out, nodes)
+	out.Flush()
+}
+
+// writeOrgChartDOT writes nodes as a DOT digraph.
+func writeOrgChartDOT(out *bufio.Writer, nodes []orgNode) {
+	exists := make(map[bson.ObjectID]bool, len(nodes))
+	for _, node := range nodes {
+		exists[node.ID] = true
+	}
+	reports := map[bson.ObjectID][]bson.ObjectID{}
+	var roots []bson.ObjectID
+	for _, node := range nodes {
+		if node.ManagerID != nil && exists[*node.ManagerID] {
+			reports[*node.ManagerID] = append(reports[*node.ManagerID], node.ID)
+		} else {
+			roots = append(roots, node.ID)
+		}
+	}
+
+	// Walk down from the roots, visiting each employee once.
+	reached := map[bson.ObjectID]bool{}
+	stack := append([]bson.ObjectID{}, roots...)
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if reached[id] {
+			continue
+		}
+		reached[id] = true
+		stack = append(stack, reports[id]...)
+	}
+
+	fmt.Fprintln(out, "digraph org_chart {")
+	fmt.Fprintln(out, "  rankdir=TB;")
+	fmt.Fprintln(out, "  node [shape=box];")
+	for _, node := range nodes {
+		attrs := fmt.Sprintf("label=%s", dotQuote(node.Name))
+		if !reached[node.ID] {
+			attrs += ", color=red"
+		}
+		fmt.Fprintf(out, "  %s [%s];\n", dotQuote(node.ID.Hex()), attrs)
+	}
+
+	managers := make([]bson.ObjectID, 0, len(reports))
+	for id := range reports {
+		managers = append(managers, id)
+	}
+	sort.Slice(managers, func(i, j int) bool { return managers[i].Hex() < managers[j].Hex() })
+	for _, manager := range managers {
+		for _, report := range reports[manager] {
+			fmt.Fprintf(out, "  %s -> %s;\n", dotQuote(manager.Hex()), dotQuote(report.Hex()))
+		}
+	}
+	fmt.Fprintln(out, "}")
+}
+
+// dotQuote returns s as a double-quoted DOT string. Line breaks are dropped
+// since they would end up as literal text in the label.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+	return `"` + s + `"`
+}
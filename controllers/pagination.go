@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Page sizes of the list endpoints, set from DEFAULT_PAGE_SIZE and
+// MAX_PAGE_SIZE by ConnectToMongoDB.
+var (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// pageSizesFromEnv reads DEFAULT_PAGE_SIZE, the ?limit= used when only
+// ?cursor= is given, and MAX_PAGE_SIZE, the largest ?limit= accepted. Unset or
+// invalid values keep the built-in 20 and 100; a default above the maximum is
+// an error.
+func pageSizesFromEnv() (defaultSize, maxSize int, err error) {
+	defaultSize = envInt("DEFAULT_PAGE_SIZE", defaultPageSize)
+	maxSize = envInt("MAX_PAGE_SIZE", maxPageSize)
+	if defaultSize > maxSize {
+		return 0, 0, fmt.Errorf("DEFAULT_PAGE_SIZE (%d) must not exceed MAX_PAGE_SIZE (%d)", defaultSize, maxSize)
+	}
+	return defaultSize, maxSize, nil
+}
+
+// errInvalidCursor is returned for cursor tokens that fail to decode or verify.
+var errInvalidCursor = errors.New("invalid or tampered cursor")
+
+// errCursorSortMismatch is returned for a cursor issued for another ?sort=.
+var errCursorSortMismatch = errors.New("cursor does not match the requested sort")
+
+// pageParams are the pagination controls of a list request.
+type pageParams struct {
+	Limit int
+	After *pageCursor
+}
+
+// pageCursor is the decoded content of an opaque cursor token: the sort key(s)
+// of the last item on the previous page.
+type pageCursor struct {
+	ID bson.ObjectID `json:"id"`
+	// Sort and Values are set for pages ordered by ?sort=: the normalized sort
+	// parameter and the BSON-encoded sort values of the last item.
+	Sort   string `json:"sort,omitempty"`
+	Values []byte `json:"values,omitempty"`
+}
+
+// pageInfo is the pagination metadata returned alongside a page of results.
+type pageInfo struct {
+	Limit      int    `json:"limit"`
+	HasMore    bool   `json:"hasMore"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// parsePageParams reads ?limit= and ?cursor=. paginated is false when neither is
+// present, in which case callers keep their unpaginated behavior.
+func parsePageParams(query url.Values) (params pageParams, paginated bool, err error) {
+	params.Limit = defaultPageSize
+
+	if raw := query.Get("limit"); raw != "" {
+		paginated = true
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxPageSize {
+			return params, true, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+		}
+		params.Limit = n
+	}
+
+	if raw := query.Get("cursor"); raw != "" {
+		paginated = true
+		cursor, err := decodeCursor(raw)
+		if err != nil {
+			return params, true, err
+		}
+		params.After = &cursor
+	}
+
+	return params, paginated, nil
+}
+
+// cursorKey returns the HMAC key that signs cursor tokens. CURSOR_SECRET should
+// be set when running several instances, so a token issued by one instance is
+// accepted by the others; otherwise a random per-process key is used and
+// tokens stop working after a restart.
+var cursorKey = sync.OnceValue(func() []byte {
+	if secret := os.Getenv("CURSOR_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+})
+
+// encodeCursor serializes c as an opaque, signed, URL-safe token. Clients must
+// treat it as a black box; its content may change with the sort keys.
+func encodeCursor(c pageCursor) string {
+	payload, _ := json.Marshal(c)
+	mac := hmac.New(sha256.New, cursorKey())
+	mac.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// decodeCursor verifies and decodes a token produced by encodeCursor.
+func decodeCursor(token string) (pageCursor, error) {
+	var c pageCursor
+
+	encodedPayload, encodedMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return c, errInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return c, errInvalidCursor
+	}
+	sum, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil {
+		return c, errInvalidCursor
+	}
+
+	mac := hmac.New(sha256.New, cursorKey())
+	mac.Write(payload)
+	if !hmac.Equal(sum, mac.Sum(nil)[:16]) {
+		return c, errInvalidCursor
+	}
+
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return c, errInvalidCursor
+	}
+	return c, nil
+}
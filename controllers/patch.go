@@ -0,0 +1,413 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// patchField is a path that PATCH may set, with the Go type its value decodes
+// into and the validate tag of the model field it comes from.
+type patchField struct {
+	typ      reflect.Type
+	tag      string
+	required bool
+}
+
+// patchableFields allowlists the paths accepted by PatchEmployee: the
+// client-writable top-level fields plus the sub-fields of address, addressed
+// as "address.city" etc. Status has its own endpoint.
+var patchableFields = func() map[string]patchField {
+	top := []string{"name", "email", "phone", "department", "address", "salary", "managerId", "joinedAt", "terminationDate", "tags"}
+	fields := map[string]patchField{}
+	addModelFields(fields, reflect.TypeOf(models.Employee{}), "", top)
+	addModelFields(fields, reflect.TypeOf(models.Address{}), "address.", nil)
+	return fields
+}()
+
+// addModelFields adds the fields of struct type t named in names (all of them
+// when names is nil), keyed by prefix + JSON name.
+func addModelFields(fields map[string]patchField, t reflect.Type, prefix string, names []string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || (names != nil && !contains(names, name)) {
+			continue
+		}
+		typ := field.Type
+		if typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		tag := field.Tag.Get("validate")
+		fields[prefix+name] = patchField{
+			typ:      typ,
+			tag:      tag,
+			required: strings.HasPrefix(tag, "required"),
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// PatchEmployee - HTTP handler to update only the fields present in the body
+//
+// The body maps field paths to new values, e.g. {"phone": "555-0100",
+// "address.city": "Boston"}; only the paths in patchableFields are accepted,
+// and each value is validated by the rules of its field, after email and phone
+// are normalized as for PUT (see NORMALIZE_TRANSFORMS). null removes an
+// optional field. If-Match is honored as for PUT.
+//
+// With Content-Type: application/merge-patch+json the body is a JSON Merge
+// Patch (RFC 7386) instead: keys are field names, not paths, and an object
+// given for address is merged into the stored address, with null removing a
+// sub-field, rather than replacing it. Arrays such as tags are replaced.
+func PatchEmployee(w http.ResponseWriter, r *http.Request) {
+	employeeID := employeeIDFromRequest(r)
+	w.Header().Set("Accept-Patch", mediaTypeJSON+", "+mediaTypeMergePatch)
+
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+	if isMergePatch(r) {
+		var err error
+		if body, err = mergePatchPaths(body); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if len(body) == 0 {
+		writeError(w, http.StatusBadRequest, "Request body must set at least one field")
+		return
+	}
+
+	set, unset, err := buildPatch(body)
+	if err != nil {
+		var invalid patchValidationError
+		if errors.As(err, &invalid) {
+			writeError(w, http.StatusUnprocessableEntity, invalid.Error())
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if email, ok := set["email"].(string); ok && !emailDomainAccepted(w, r, email) {
+		return
+	}
+
+	current, err := getOneEmployee(r.Context(), employeeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errEmployeeNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeServerError(w, "Failed to retrieve employee", err)
+		}
+		return
+	}
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" && !etagMatches(ifMatch, employeeETag(current)) {
+		writeError(w, http.StatusPreconditionFailed, errPreconditionFailed.Error())
+		return
+	}
+
+	// Sub-field updates must leave a valid address behind, e.g. with a country.
+	// That check is made against current, so the write must apply to it too.
+	address, addressPatched := patchedAddress(current.Address, set, unset)
+	if addressPatched {
+		if err := validate.Struct(address); err != nil {
+			writeValidationError(w, err)
+			return
+		}
+	}
+
+	if managerID, ok := set["managerId"].(bson.ObjectID); ok {
+		checked := current
+		checked.ManagerID = &managerID
+		if department, ok := set["department"].(string); ok {
+			checked.Department = department
+		}
+		if err := checkManagerAssignment(r.Context(), checked, &current.ID); err != nil {
+			if errors.Is(err, errInvalidManager) {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to check manager: %v", err))
+			return
+		}
+	}
+
+	employee, err := patchOneEmployee(r.Context(), current, set, unset, ifMatch != "" || addressPatched)
+	if err != nil {
+		switch {
+		case errors.Is(err, errPreconditionFailed) && ifMatch != "":
+			writeError(w, http.StatusPreconditionFailed, err.Error())
+		case errors.Is(err, errPreconditionFailed):
+			writeError(w, http.StatusConflict, "employee was modified concurrently; retry the request")
+		case errors.Is(err, errEmployeeNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		case mongo.IsDuplicateKeyError(err):
+			writeError(w, http.StatusConflict, duplicateKeyMessage())
+		default:
+			writeServerError(w, "Failed to update employee", err)
+		}
+		return
+	}
+
+	if !canViewPII(r) {
+		employee = maskEmployee(employee)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Employee updated successfully",
+		"data":    employee,
+	})
+}
+
+// patchValidationError is a patch value that failed its field's validation.
+type patchValidationError struct {
+	messages []string
+}
+
+func (e patchValidationError) Error() string { return strings.Join(e.messages, ", ") }
+
+// mediaTypeMergePatch is the Content-Type of a JSON Merge Patch body.
+const mediaTypeMergePatch = "application/merge-patch+json"
+
+// isMergePatch reports whether the request body is a JSON Merge Patch.
+func isMergePatch(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == mediaTypeMergePatch
+}
+
+// mergePatchPaths translates a JSON Merge Patch into the paths of buildPatch:
+// the members of an address object become address.* paths, so that they are
+// merged into the stored address. null for address itself still removes it.
+func mergePatchPaths(patch map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+	body := map[string]json.RawMessage{}
+	for name, raw := range patch {
+		if strings.Contains(name, ".") {
+			return nil, fmt.Errorf("field %q cannot be patched", name)
+		}
+		trimmed := bytes.TrimSpace(raw)
+		if name != "address" || len(trimmed) == 0 || trimmed[0] != '{' {
+			body[name] = raw
+			continue
+		}
+		var members map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &members); err != nil {
+			return nil, fmt.Errorf("invalid value for %q: %v", name, err)
+		}
+		for member, value := range members {
+			body[name+"."+member] = value
+		}
+	}
+	return body, nil
+}
+
+// buildPatch decodes and validates each path of body into the $set and $unset
+// parts of the update.
+func buildPatch(body map[string]json.RawMessage) (set, unset bson.M, err error) {
+	set, unset = bson.M{}, bson.M{}
+	paths := make([]string, 0, len(body))
+	for path := range body {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var invalid []string
+	for _, path := range paths {
+		field, ok := patchableFields[path]
+		if !ok {
+			return nil, nil, fmt.Errorf("field %q cannot be patched", path)
+		}
+		// Setting a sub-document and one of its fields at once is ambiguous.
+		if parent, _, nested := strings.Cut(path, "."); nested {
+			if _, ok := body[parent]; ok {
+				return nil, nil, fmt.Errorf("fields %q and %q cannot be patched together", parent, path)
+			}
+		}
+
+		raw := body[path]
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			if field.required {
+				invalid = append(invalid, validationMessage(path, "required", ""))
+				continue
+			}
+			unset[path] = ""
+			continue
+		}
+
+		value := reflect.New(field.typ)
+		if err := json.Unmarshal(raw, value.Interface()); err != nil {
+			return nil, nil, fmt.Errorf("invalid value for %q: %v", path, err)
+		}
+		switch path {
+		case "email":
+			normalizeContact(value.Interface().(*string), new(string))
+		case "phone":
+			normalizeContact(new(string), value.Interface().(*string))
+		}
+		if messages := validatePatchValue(path, field, value.Elem().Interface()); len(messages) > 0 {
+			invalid = append(invalid, messages...)
+			continue
+		}
+		set[path] = normalizePatchValue(value.Elem().Interface())
+	}
+
+	if len(invalid) > 0 {
+		return nil, nil, patchValidationError{messages: invalid}
+	}
+	return set, unset, nil
+}
+
+// validatePatchValue applies the validation rules of field to value.
+func validatePatchValue(path string, field patchField, value interface{}) []string {
+	var err error
+	if field.typ.Kind() == reflect.Struct && field.typ != timeType {
+		err = validate.Struct(value)
+	} else {
+		err = validate.Var(value, field.tag)
+	}
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []string{fmt.Sprintf("Validation error: %v", err)}
+	}
+	messages := make([]string, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		name := path
+		if fieldErr.Field() != "" {
+			name = fieldErr.Field()
+		}
+		messages = append(messages, validationMessage(name, fieldErr.Tag(), fieldErr.Param()))
+	}
+	return messages
+}
+
+// normalizePatchValue stores times in UTC and tags normalized, as the other
+// write paths do.
+func normalizePatchValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC()
+	case []string:
+		return normalizeTags(v)
+	}
+	return value
+}
+
+// patchedAddress returns the address that results from applying the address
+// sub-field changes to current, and false when the patch has none.
+func patchedAddress(current *models.Address, set, unset bson.M) (models.Address, bool) {
+	var address models.Address
+	if current != nil {
+		address = *current
+	}
+	changed := false
+	fields := map[string]*string{
+		"address.street":     &address.Street,
+		"address.city":       &address.City,
+		"address.state":      &address.State,
+		"address.postalCode": &address.PostalCode,
+		"address.country":    &address.Country,
+	}
+	for path, target := range fields {
+		if value, ok := set[path].(string); ok {
+			*target = value
+			changed = true
+		}
+		if _, ok := unset[path]; ok {
+			*target = ""
+			changed = true
+		}
+	}
+	return address, changed
+}
+
+// patchOneEmployee applies a partial update to current and returns the updated
+// employee. With onlyIfCurrent the update only applies if current is still the
+// stored version, otherwise errPreconditionFailed is returned.
+func patchOneEmployee(ctx context.Context, current models.Employee, set, unset bson.M, onlyIfCurrent bool) (models.Employee, error) {
+	var employee models.Employee
+	if err := checkDatabase(); err != nil {
+		return employee, err
+	}
+
+	set["updatedAt"] = time.Now().UTC()
+	if phone, ok := set["phone"].(string); ok {
+		set["phone"] = encryptPhone(phone)
+		set["phoneDigits"] = encryptPhoneDigits(phoneDigits(phone))
+	}
+	if _, ok := unset["phone"]; ok {
+		unset["phoneDigits"] = ""
+	}
+	if email, ok := set["email"].(string); ok {
+		set["email"] = encryptEmail(email)
+	}
+	if name, ok := set["name"].(string); ok {
+		set["nameSearch"] = foldSearchText(name)
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	filter := withNotDeleted(bson.M{"_id": current.ID})
+	if onlyIfCurrent {
+		if current.UpdatedAt.IsZero() {
+			filter["updatedAt"] = bson.M{"$exists": false}
+		} else {
+			filter["updatedAt"] = current.UpdatedAt
+		}
+	}
+
+	var before bson.M
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&before)
+	detailCache().invalidate(current.ID.Hex())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		if onlyIfCurrent {
+			return employee, errPreconditionFailed
+		}
+		return employee, fmt.Errorf("%w: %s", errEmployeeNotFound, current.ID.Hex())
+	}
+	if err != nil {
+		return employee, fmt.Errorf("error updating employee: %w", err)
+	}
+	fmt.Println("Patched employee with id:", current.ID.Hex())
+
+	after, err := findDocument(ctx, current.ID)
+	if err != nil {
+		return employee, err
+	}
+	if err := recordAudit(ctx, auditUpdate, current.ID, before, after); err != nil {
+		return employee, err
+	}
+	return employee, decodeDocument(after, &employee)
+}
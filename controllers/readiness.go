@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// dbReady is set once ConnectToMongoDB has opened the collections. Reading it
+// before the collection globals also orders those reads after their writes.
+var dbReady atomic.Bool
+
+// RequireDatabase responds 503 Service Unavailable to requests arriving before
+// the MongoDB connection is established, instead of letting handlers
+// dereference nil collections. The validator needs no such guard: it is set up
+// in init, before main runs.
+func RequireDatabase(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !dbReady.Load() {
+			w.Header().Set("Retry-After", "5")
+			writeError(w, http.StatusServiceUnavailable, "service not ready")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// errDatabaseNotInitialized is returned by the data-access functions when
+// they are called before ConnectToMongoDB has opened the collections.
+var errDatabaseNotInitialized = errors.New("database not initialized")
+
+// checkDatabase returns errDatabaseNotInitialized until the collections are
+// open. RequireDatabase keeps requests from getting this far; the check
+// covers the paths that bypass it, such as a route registered without the
+// middleware or a command run before connecting, which would otherwise
+// panic on a nil collection.
+func checkDatabase() error {
+	if !dbReady.Load() {
+		return errDatabaseNotInitialized
+	}
+	return nil
+}
+
+// startSession starts a session for a transaction, failing with
+// errDatabaseNotInitialized rather than panicking before ConnectToMongoDB.
+func startSession() (*mongo.Session, error) {
+	if err := checkDatabase(); err != nil {
+		return nil, err
+	}
+	session, err := mongoClient.StartSession()
+	if err != nil {
+		return nil, fmt.Errorf("error starting session: %w", err)
+	}
+	return session, nil
+}
+
+// writeServerError responds 500 with "msg: err", or 503 as RequireDatabase
+// does when err is errDatabaseNotInitialized.
+func writeServerError(w http.ResponseWriter, msg string, err error) {
+	if errors.Is(err, errDatabaseNotInitialized) {
+		w.Header().Set("Retry-After", "5")
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, fmt.Sprintf("%s: %v", msg, err))
+}
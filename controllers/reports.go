@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// GetDirectReports - HTTP handler to list the employees reporting to an employee
+//
+// The result is always cursor-paginated, with ?limit= and ?cursor= as on the
+// list endpoint and in the same {"data", "pagination"} envelope as
+// GetEmployeeHistory, and accepts ?sort= and ?includeId=. 404 means the
+// manager does not exist; it is checked on the first page only.
+func GetDirectReports(w http.ResponseWriter, r *http.Request) {
+	id := employeeIDFromRequest(r)
+
+	sort, ok := parseSortParam(w, r)
+	if !ok {
+		return
+	}
+	includeID, ok := parseIncludeIDParam(w, r)
+	if !ok {
+		return
+	}
+	page, _, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if page.After == nil {
+		if _, err := getOneEmployee(r.Context(), id); err != nil {
+			switch {
+			case errors.Is(err, errEmployeeNotFound):
+				writeError(w, http.StatusNotFound, err.Error())
+			default:
+				writeServerError(w, "Failed to retrieve employee", err)
+			}
+			return
+		}
+	}
+
+	getEmployeesPage(w, r, withNotDeleted(bson.M{"managerId": id}), page, sort, includeID)
+}
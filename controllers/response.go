@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	mediaTypeJSON = "application/json"
+	mediaTypeXML  = "application/xml"
+)
+
+// readMediaTypes are the representations offered by the employee read endpoints.
+// JSON comes first so it wins whenever the client has no preference.
+var readMediaTypes = []string{mediaTypeJSON, mediaTypeXML}
+
+// employeeList is the XML document root for a list of employees.
+type employeeList struct {
+	XMLName   xml.Name          `xml:"employees"`
+	Employees []models.Employee `xml:"employee"`
+}
+
+// readMediaType returns the representation to use for a read endpoint response.
+func readMediaType(r *http.Request) string {
+	mediaType, ok := negotiateMediaType(r.Header.Get("Accept"), readMediaTypes)
+	if !ok {
+		return mediaTypeJSON
+	}
+	return mediaType
+}
+
+// writeJSON writes v as a JSON response body with the given status code.
+//
+// v is encoded before anything is written, so a value that cannot be encoded
+// (or whose MarshalJSON panics) is logged and answered with a 500 instead of a
+// truncated body under the original status. Errors writing the body, e.g. when
+// the client has gone away, are logged; the status is already sent by then.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	body, err := safeMarshalJSON(w, v)
+	if err != nil {
+		log.Printf("Warning: error encoding JSON response: %v", err)
+		status = http.StatusInternalServerError
+		body, _ = marshalJSON(w, map[string]string{"error": "Failed to encode response"})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		log.Printf("Warning: error writing JSON response: %v", err)
+	}
+}
+
+// safeMarshalJSON is marshalJSON that turns a panic during encoding into an error.
+func safeMarshalJSON(w http.ResponseWriter, v interface{}) (body []byte, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic while encoding: %v", p)
+		}
+	}()
+	return marshalJSON(w, v)
+}
+
+// marshalJSON encodes v the same way writeJSON would for w, with the keys
+// renamed by FieldAliases and indented by PrettyJSON when they apply.
+func marshalJSON(w http.ResponseWriter, v interface{}) ([]byte, error) {
+	aliases := responseAliases(w)
+	if aliases == nil {
+		if wantsPrettyJSON(w) {
+			return json.MarshalIndent(v, "", "  ")
+		}
+		return json.Marshal(v)
+	}
+
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if body, err = renameJSONKeys(body, aliases); err != nil {
+		return nil, err
+	}
+	if wantsPrettyJSON(w) {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, body, "", "  "); err != nil {
+			return nil, err
+		}
+		body = indented.Bytes()
+	}
+	return body, nil
+}
+
+// marshalXML encodes v as an XML document, indented when w asks for pretty output.
+func marshalXML(w http.ResponseWriter, v interface{}) ([]byte, error) {
+	var body []byte
+	var err error
+	if wantsPrettyJSON(w) {
+		body, err = xml.MarshalIndent(v, "", "  ")
+	} else {
+		body, err = xml.Marshal(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// prettyWriter marks a response whose JSON should be indented.
+type prettyWriter struct {
+	http.ResponseWriter
+}
+
+func (w *prettyWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func (w *prettyWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *prettyWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// PrettyJSON makes every JSON (and XML) response of a request indented when the
+// request carries ?pretty=true. Compact output remains the default.
+func PrettyJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if pretty, _ := strconv.ParseBool(r.URL.Query().Get("pretty")); pretty {
+			w = &prettyWriter{ResponseWriter: w}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// wantsPrettyJSON reports whether w, or any writer it wraps, is a prettyWriter.
+func wantsPrettyJSON(w http.ResponseWriter) bool {
+	for {
+		if _, ok := w.(*prettyWriter); ok {
+			return true
+		}
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return false
+		}
+		w = unwrapper.Unwrap()
+	}
+}
+
+// parseIncludeIDParam reads ?includeId= (default true). includeId=false
+// leaves the database id out of the employees of a JSON response, for clients
+// that only display data. XML cannot omit the id, so asking for both is
+// rejected. It writes the error response and returns false when invalid.
+func parseIncludeIDParam(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	raw := r.URL.Query().Get("includeId")
+	if raw == "" {
+		return true, true
+	}
+	includeID, err := strconv.ParseBool(raw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Query parameter 'includeId' must be true or false")
+		return false, false
+	}
+	if !includeID && readMediaType(r) == mediaTypeXML {
+		writeError(w, http.StatusBadRequest, "includeId=false is only supported for JSON responses")
+		return false, false
+	}
+	return includeID, true
+}
+
+// withoutIDs clears the id of each employee, so it is omitted from JSON.
+func withoutIDs(employees []models.Employee) {
+	for i := range employees {
+		employees[i].ID = bson.NilObjectID
+	}
+}
+
+// writeError writes a JSON error body of the form {"error": msg}.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// writeCacheableJSON writes v as a 200 JSON response carrying an ETag derived
+// from the encoded body. A matching If-None-Match yields 304 Not Modified.
+// HEAD requests receive the same headers; net/http discards the body.
+func writeCacheableJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	writeJSONWithETag(w, r, v, "")
+}
+
+// writeJSONWithETag is writeCacheableJSON with a caller-supplied ETag; an empty
+// etag is derived from the encoded body.
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, v interface{}, etag string) {
+	writeCacheable(w, r, mediaTypeJSON, v, etag)
+}
+
+// writeCacheable encodes v as contentType (JSON or XML) and writes it as a
+// cacheable 200 response; see writeCacheableJSON.
+func writeCacheable(w http.ResponseWriter, r *http.Request, contentType string, v interface{}, etag string) {
+	var body []byte
+	var err error
+	if contentType == mediaTypeXML {
+		body, err = marshalXML(w, v)
+	} else {
+		body, err = marshalJSON(w, v)
+	}
+	if err != nil {
+		log.Printf("Warning: error encoding response: %v", err)
+		writeError(w, http.StatusInternalServerError, "Failed to encode response")
+		return
+	}
+	body = append(body, '\n')
+
+	if etag == "" {
+		etag = computeETag(body)
+	}
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		log.Printf("Warning: error writing response: %v", err)
+	}
+}
+
+// computeETag returns a strong, quoted ETag for the given response body.
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match / If-Match header value
+// contains etag or the "*" wildcard.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// employeeETag returns the ETag of the stored employee document. It is derived
+// from the unmasked record so that every caller sees the same validator for the
+// same version, and is what If-Match on updates is compared against.
+// lastSeenAt is left out: TouchEmployee sets it without bumping updatedAt, and
+// a touch must not fail the If-Match of a client editing the employee. A
+// conditional GET may therefore answer 304 with an older lastSeenAt.
+func employeeETag(employee models.Employee) string {
+	employee.LastSeenAt = nil
+	body, err := json.Marshal(employee)
+	if err != nil {
+		return ""
+	}
+	return computeETag(append(body, '\n'))
+}
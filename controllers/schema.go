@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// serverManagedFields are set by the server and ignored in request bodies.
+var serverManagedFields = map[string]bool{
+	"id":             true,
+	"createdAt":      true,
+	"createdBy":      true,
+	"updatedAt":      true,
+	"lastSeenAt":     true,
+	"deletedAt":      true,
+	"deletedBy":      true,
+	"deletionReason": true,
+	"mergedInto":     true,
+	"anonymizedAt":   true,
+}
+
+// fieldSchema describes one field of a model for dynamically generated forms.
+// The exclusive flags mark Minimum/Maximum bounds coming from gt/lt rules.
+type fieldSchema struct {
+	Name             string        `json:"name"`
+	Type             string        `json:"type"`
+	Format           string        `json:"format,omitempty"`
+	Required         bool          `json:"required"`
+	ReadOnly         bool          `json:"readOnly,omitempty"`
+	Enum             []string      `json:"enum,omitempty"`
+	Minimum          *float64      `json:"minimum,omitempty"`
+	Maximum          *float64      `json:"maximum,omitempty"`
+	ExclusiveMinimum bool          `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum bool          `json:"exclusiveMaximum,omitempty"`
+	MinDate          string        `json:"minDate,omitempty"`
+	MinLength        *int          `json:"minLength,omitempty"`
+	MaxLength        *int          `json:"maxLength,omitempty"`
+	MaxItems         *int          `json:"maxItems,omitempty"`
+	Pattern          string        `json:"pattern,omitempty"`
+	Fields           []fieldSchema `json:"fields,omitempty"`
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	objectIDType = reflect.TypeOf(bson.ObjectID{})
+)
+
+// GetEmployeeSchema - HTTP handler to describe the employee fields and their validation rules
+//
+// The description is derived from the json and validate tags of
+// models.Employee, so it follows the model as it changes.
+func GetEmployeeSchema(w http.ResponseWriter, r *http.Request) {
+	writeCacheableJSON(w, r, map[string]interface{}{
+		"fields": describeFields(reflect.TypeOf(models.Employee{}), true),
+	})
+}
+
+// describeFields lists the JSON-visible fields of struct type t. Only the top
+// level model has server-managed fields.
+func describeFields(t reflect.Type, topLevel bool) []fieldSchema {
+	fields := []fieldSchema{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := fieldSchema{Name: name, ReadOnly: topLevel && serverManagedFields[name]}
+		describeType(&schema, field.Type)
+		applyValidateTag(&schema, field.Tag.Get("validate"))
+		fields = append(fields, schema)
+	}
+	return fields
+}
+
+// describeType fills in the type (and format) of a Go type as seen in JSON.
+func describeType(schema *fieldSchema, t reflect.Type) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		schema.Type, schema.Format = "string", "date-time"
+	case t == objectIDType:
+		schema.Type, schema.Format = "string", "objectid"
+	case t.Kind() == reflect.String:
+		schema.Type = "string"
+	case t.Kind() == reflect.Bool:
+		schema.Type = "boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		schema.Type = "integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		schema.Type = "number"
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		schema.Type = "array"
+	case t.Kind() == reflect.Struct:
+		schema.Type = "object"
+		schema.Fields = describeFields(t, false)
+	default:
+		schema.Type = t.Kind().String()
+	}
+}
+
+// applyValidateTag translates the validator rules on a field into constraints.
+// min/max bound the length of strings and the value of numbers, and max the
+// size of arrays. Rules after dive apply to array elements and are skipped.
+func applyValidateTag(schema *fieldSchema, tag string) {
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "dive" {
+			return
+		}
+		switch name {
+		case "required":
+			schema.Required = true
+		case "email":
+			schema.Format = "email"
+		case "iso3166_1_alpha2":
+			schema.Format = "iso3166-1-alpha-2"
+		case "postalcode":
+			schema.Pattern = postalCodePattern.String()
+		case "tag":
+			schema.Pattern = tagPattern.String()
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "department":
+			if allowed := allowedDepartments(); len(allowed) > 0 {
+				schema.Enum = make([]string, 0, len(allowed))
+				for department := range allowed {
+					schema.Enum = append(schema.Enum, department)
+				}
+				sort.Strings(schema.Enum)
+			}
+		case "daterange":
+			// The upper bound is the current time.
+			schema.MinDate = minClientDate.Format("2006-01-02")
+		case "gte", "min":
+			applyLowerBound(schema, param)
+		case "gt":
+			applyLowerBound(schema, param)
+			schema.ExclusiveMinimum = true
+		case "lte", "max":
+			applyUpperBound(schema, param)
+		case "lt":
+			applyUpperBound(schema, param)
+			schema.ExclusiveMaximum = true
+		}
+	}
+}
+
+func applyLowerBound(schema *fieldSchema, param string) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	if schema.Type == "string" {
+		length := int(n)
+		schema.MinLength = &length
+		return
+	}
+	schema.Minimum = &n
+}
+
+func applyUpperBound(schema *fieldSchema, param string) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch schema.Type {
+	case "string":
+		length := int(n)
+		schema.MaxLength = &length
+		return
+	case "array":
+		items := int(n)
+		schema.MaxItems = &items
+		return
+	}
+	schema.Maximum = &n
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+)
+
+// searchResult is an employee annotated with the fields that matched the query.
+type searchResult struct {
+	models.Employee
+	MatchedFields []string `json:"matchedFields"`
+}
+
+// SearchEmployees - HTTP handler to search employees by name, email or department
+//
+// ?q= is matched as a literal, case-insensitive substring, with whitespace
+// normalized: leading/trailing spaces are ignored and any run of spaces
+// matches any run of whitespace in the stored value. The list filters
+// (department, status) and ?sort= may be combined with it. With
+// ?highlight=true every result also carries matchedFields so the UI can
+// highlight the hits. Names match regardless of accents, so "Jose" finds
+// "José". Email is only searched, and reported as matched, for callers who
+// may see it unmasked, and only by complete address while it is encrypted.
+func SearchEmployees(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	q := normalizeSearchQuery(query.Get("q"))
+	if q == "" {
+		writeError(w, http.StatusBadRequest, "Query parameter 'q' is required")
+		return
+	}
+
+	highlight := false
+	if raw := query.Get("highlight"); raw != "" {
+		var err error
+		if highlight, err = strconv.ParseBool(raw); err != nil {
+			writeError(w, http.StatusBadRequest, "Query parameter 'highlight' must be true or false")
+			return
+		}
+	}
+
+	maskPII := !canViewPII(r)
+	query.Del("search")
+	filter, err := buildEmployeeFilter(query, !maskPII)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter["$or"] = searchConditions(q, !maskPII)
+
+	sort, ok := parseSortParam(w, r)
+	if !ok {
+		return
+	}
+
+	employees, truncated, err := getAllEmployees(r.Context(), filter, sort)
+	if err != nil {
+		writeServerError(w, "Failed to search employees", err)
+		return
+	}
+	if truncated {
+		w.Header().Set("X-Result-Truncated", "true")
+	}
+
+	if !highlight {
+		if maskPII {
+			for i := range employees {
+				employees[i] = maskEmployee(employees[i])
+			}
+		}
+		writeJSON(w, http.StatusOK, employees)
+		return
+	}
+
+	results := make([]searchResult, 0, len(employees))
+	for _, employee := range employees {
+		// Matches are computed on the stored values, before any masking.
+		result := searchResult{MatchedFields: matchedFields(employee, q, !maskPII)}
+		if maskPII {
+			employee = maskEmployee(employee)
+		}
+		result.Employee = employee
+		results = append(results, result)
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// matchedFields re-checks the searchable fields in Go, mirroring the
+// case-insensitive, space-normalized match done by searchConditions.
+func matchedFields(employee models.Employee, q string, allowPII bool) []string {
+	needle := strings.ToLower(normalizeSearchQuery(q))
+	folded := foldSearchText(q)
+	fields := []string{}
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{"name", employee.Name},
+		{"email", employee.Email},
+		{"department", employee.Department},
+	} {
+		if field.name == "email" && !allowPII {
+			continue
+		}
+		if strings.Contains(strings.ToLower(normalizeSearchQuery(field.value)), needle) {
+			fields = append(fields, field.name)
+		} else if field.name == "name" && strings.Contains(foldSearchText(field.value), folded) {
+			fields = append(fields, field.name)
+		}
+	}
+	return fields
+}
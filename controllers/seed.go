@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	seedFirstNames = []string{"Aarav", "Olivia", "Liam", "Priya", "Noah", "Emma", "Mateo", "Aisha", "Lucas", "Mei", "Ethan", "Sofia", "Kenji", "Amara", "Oliver", "Zara"}
+	seedLastNames  = []string{"Sharma", "Smith", "Garcia", "Chen", "Johnson", "Okafor", "Mueller", "Rossi", "Tanaka", "Kim", "Silva", "Nguyen", "Brown", "Patel", "Khan", "Dubois"}
+	seedCities     = []string{"Springfield", "Riverside", "Fairview", "Madison", "Georgetown", "Franklin"}
+	seedStreets    = []string{"Main St", "Oak Ave", "Maple Dr", "Cedar Ln", "Elm St", "Park Rd"}
+	// seedDepartments are used when DEPARTMENTS does not restrict the choice.
+	seedDepartments = []string{"Engineering", "Sales", "Marketing", "Finance", "HR", "Support"}
+)
+
+// SeedEmployees inserts count generated employees for demos and local
+// development. The same seed always produces the same employees. Seeding is
+// skipped when the collection already holds employees unless force is set;
+// generated employees whose unique key (see UNIQUE_KEY) already exists are
+// rejected by the unique index and not counted. It returns how many employees were inserted.
+func SeedEmployees(ctx context.Context, count int, seed uint64, force bool) (int, error) {
+	if !force {
+		existing, err := collection.CountDocuments(ctx, bson.M{})
+		if err != nil {
+			return 0, fmt.Errorf("error counting employees: %w", err)
+		}
+		if existing > 0 {
+			fmt.Printf("Collection already has %d employees; skipping seed (use -force to seed anyway)\n", existing)
+			return 0, nil
+		}
+	}
+
+	departments := seedDepartments
+	if allowed := allowedDepartments(); len(allowed) > 0 {
+		departments = make([]string, 0, len(allowed))
+		for department := range allowed {
+			departments = append(departments, department)
+		}
+		// Map iteration order is random; sort to keep the output reproducible.
+		sort.Strings(departments)
+	}
+
+	rng := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+	employees := make([]models.Employee, 0, count)
+	for i := 0; i < count; i++ {
+		employee := fakeEmployee(rng, i, departments)
+		if err := validate.Struct(employee); err != nil {
+			return 0, fmt.Errorf("generated employee %d is invalid: %w", i, err)
+		}
+		employees = append(employees, employee)
+	}
+
+	// Audit entries name the seeder as the actor.
+	ctx = context.WithValue(ctx, principalKey{}, Principal{Name: "seed", Role: RoleAdmin})
+
+	inserted := 0
+	batchSize := envInt("IMPORT_BATCH_SIZE", 500)
+	for start := 0; start < len(employees); start += batchSize {
+		end := min(start+batchSize, len(employees))
+		// Rejected rows are emails that already exist; they are skipped.
+		n, _, err := insertManyEmployees(ctx, employees[start:end])
+		inserted += n
+		if err != nil {
+			return inserted, err
+		}
+	}
+	fmt.Printf("Seeded %d of %d employees\n", inserted, count)
+	return inserted, nil
+}
+
+// fakeEmployee generates the i-th seed employee. The index is part of the
+// email so that every generated email is unique.
+func fakeEmployee(rng *rand.Rand, i int, departments []string) models.Employee {
+	first := seedFirstNames[rng.IntN(len(seedFirstNames))]
+	last := seedLastNames[rng.IntN(len(seedLastNames))]
+
+	salary := float64(40000 + rng.IntN(160)*1000)
+	joinedAt := time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, rng.IntN(3650))
+
+	status := models.StatusActive
+	switch n := rng.IntN(20); {
+	case n == 0:
+		status = models.StatusTerminated
+	case n == 1:
+		status = models.StatusOnLeave
+	}
+
+	employee := models.Employee{
+		Name:       first + " " + last,
+		Email:      fmt.Sprintf("%s.%s.%d@example.com", strings.ToLower(first), strings.ToLower(last), i+1),
+		Phone:      fmt.Sprintf("555-%03d-%04d", rng.IntN(1000), rng.IntN(10000)),
+		Department: departments[rng.IntN(len(departments))],
+		Address: &models.Address{
+			Street:     fmt.Sprintf("%d %s", 1+rng.IntN(9999), seedStreets[rng.IntN(len(seedStreets))]),
+			City:       seedCities[rng.IntN(len(seedCities))],
+			PostalCode: fmt.Sprintf("%05d", rng.IntN(100000)),
+			Country:    "US",
+		},
+		Salary:   &salary,
+		Status:   status,
+		JoinedAt: &joinedAt,
+	}
+	if status == models.StatusTerminated {
+		terminated := joinedAt.AddDate(0, rng.IntN(24)+1, 0)
+		if now := time.Now().UTC(); terminated.After(now) {
+			terminated = now
+		}
+		employee.TerminationDate = &terminated
+	}
+	return employee
+}
@@ -0,0 +1,252 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// restoreBatchSize is how many documents a restore writes per request to MongoDB.
+const restoreBatchSize = 1000
+
+// Restore modes, chosen with ?mode=.
+const (
+	restoreUpsert  = "upsert"
+	restoreReplace = "replace"
+)
+
+// GetSnapshot - HTTP handler to download every stored employee document as one JSON snapshot
+//
+// The body is {"collection", "takenAt", "documents": [...], "count"}, each
+// document in canonical Extended JSON exactly as stored: soft-deleted
+// employees included, encrypted fields still encrypted. It is streamed from
+// the cursor, and POST /admin/restore takes it back as is.
+func GetSnapshot(w http.ResponseWriter, r *http.Request) {
+	if err := checkDatabase(); err != nil {
+		writeServerError(w, "Failed to take snapshot", err)
+		return
+	}
+	cur, err := listCollection.Find(r.Context(), bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		writeServerError(w, "Failed to take snapshot", err)
+		return
+	}
+	defer closeCursor(r.Context(), cur)
+
+	clearWriteDeadline(w)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="employees-snapshot.json"`)
+	w.WriteHeader(http.StatusOK)
+	out := bufio.NewWriter(w)
+	defer out.Flush()
+
+	header, _ := json.Marshal(collection.Name())
+	fmt.Fprintf(out, `{"collection":%s,"takenAt":"%s","documents":[`, header, time.Now().UTC().Format(time.RFC3339))
+	count := 0
+	for cur.Next(r.Context()) {
+		doc, err := bson.MarshalExtJSON(cur.Current, true, false)
+		if err != nil {
+			// Headers are already sent; the truncated body will not parse.
+			log.Printf("Snapshot aborted: error encoding document: %v", err)
+			return
+		}
+		if count > 0 {
+			out.WriteByte(',')
+		}
+		out.WriteByte('\n')
+		out.Write(doc)
+		count++
+	}
+	if err := cur.Err(); err != nil {
+		log.Printf("Snapshot aborted: cursor error: %v", err)
+		return
+	}
+	fmt.Fprintf(out, "\n],\"count\":%d}\n", count)
+	fmt.Printf("Took a snapshot of %d employee documents\n", count)
+}
+
+// RestoreSnapshot - HTTP handler to load a snapshot taken by GetSnapshot
+//
+// ?mode=upsert, the default, writes every document of the snapshot over the
+// stored one with the same _id, inserting those missing, and leaves other
+// documents alone. ?mode=replace first deletes the stored employees that are
+// not in the snapshot, so the collection ends up holding exactly the
+// snapshot. The whole body is parsed before anything is written, but the
+// writes are not atomic: a failure part way through leaves the documents
+// written so far. Employees of the snapshot are only ever overwritten, never
+// deleted, so a failed restore does not lose them. Documents are restored as
+// they are, without validation and without audit entries. The response
+// reports the counts.
+func RestoreSnapshot(w http.ResponseWriter, r *http.Request) {
+	clearDeadlines(w)
+	mode := r.URL.Query().Get("mode")
+	switch mode {
+	case "":
+		mode = restoreUpsert
+	case restoreUpsert, restoreReplace:
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("mode must be %s or %s", restoreUpsert, restoreReplace))
+		return
+	}
+
+	var snapshot struct {
+		Documents []json.RawMessage `json:"documents"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&snapshot); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+	docs := make([]bson.D, len(snapshot.Documents))
+	for i, raw := range snapshot.Documents {
+		if err := bson.UnmarshalExtJSON(raw, false, &docs[i]); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("documents[%d]: %v", i, err))
+			return
+		}
+		if documentID(docs[i]) == nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("documents[%d]: missing _id", i))
+			return
+		}
+	}
+	if err := checkDatabase(); err != nil {
+		writeServerError(w, "Failed to restore snapshot", err)
+		return
+	}
+
+	counts := map[string]interface{}{"mode": mode, "documents": len(docs)}
+	err := restoreDocuments(r.Context(), mode, docs, counts)
+	detailCache().purge()
+	if err != nil {
+		status := http.StatusInternalServerError
+		if mongo.IsDuplicateKeyError(err) {
+			status = http.StatusConflict
+		}
+		writeError(w, status, fmt.Sprintf("Failed to restore snapshot: %v", err))
+		return
+	}
+	fmt.Printf("Restored %d employee documents (%s)\n", len(docs), mode)
+
+	counts["message"] = "Snapshot restored successfully"
+	writeJSON(w, http.StatusOK, counts)
+}
+
+// restoreDocuments writes docs in batches as mode says, adding the number of
+// documents inserted, replaced and, for restoreReplace, deleted to counts as
+// it goes.
+func restoreDocuments(ctx context.Context, mode string, docs []bson.D, counts map[string]interface{}) error {
+	var deleted, inserted, replaced int64
+	defer func() {
+		if mode == restoreReplace {
+			counts["deleted"] = deleted
+		}
+		counts["replaced"] = replaced
+		counts["inserted"] = inserted
+	}()
+
+	// Employees missing from the snapshot are deleted first, which also frees
+	// the unique keys they hold for the snapshot's documents.
+	if mode == restoreReplace {
+		var err error
+		if deleted, err = deleteDocumentsNotIn(ctx, docs); err != nil {
+			return err
+		}
+	}
+
+	for start := 0; start < len(docs); start += restoreBatchSize {
+		batch := docs[start:min(start+restoreBatchSize, len(docs))]
+		writes := make([]mongo.WriteModel, len(batch))
+		for i, doc := range batch {
+			writes[i] = mongo.NewReplaceOneModel().SetFilter(bson.D{{Key: "_id", Value: documentID(doc)}}).SetReplacement(doc).SetUpsert(true)
+		}
+		result, err := collection.BulkWrite(ctx, writes)
+		if result != nil {
+			inserted += result.InsertedCount + result.UpsertedCount
+			replaced += result.MatchedCount
+		}
+		if err != nil {
+			return fmt.Errorf("error writing documents %d to %d: %w", start, start+len(batch)-1, err)
+		}
+	}
+	return nil
+}
+
+// deleteDocumentsNotIn deletes, in batches, every stored employee whose _id
+// is not one of those of docs, and returns how many were deleted.
+func deleteDocumentsNotIn(ctx context.Context, docs []bson.D) (int64, error) {
+	keep := make(map[string]bool, len(docs))
+	for _, doc := range docs {
+		keep[documentIDKey(documentID(doc))] = true
+	}
+
+	cur, err := collection.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, fmt.Errorf("error listing employees: %w", err)
+	}
+	defer closeCursor(ctx, cur)
+
+	var deleted int64
+	var ids bson.A
+	flush := func() error {
+		result, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+		if result != nil {
+			deleted += result.DeletedCount
+		}
+		if err != nil {
+			return fmt.Errorf("error deleting employees: %w", err)
+		}
+		ids = ids[:0]
+		return nil
+	}
+	for cur.Next(ctx) {
+		var doc struct {
+			ID interface{} `bson:"_id"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return deleted, fmt.Errorf("error decoding employee: %w", err)
+		}
+		if keep[documentIDKey(doc.ID)] {
+			continue
+		}
+		ids = append(ids, doc.ID)
+		if len(ids) == restoreBatchSize {
+			if err := flush(); err != nil {
+				return deleted, err
+			}
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return deleted, fmt.Errorf("error listing employees: %w", err)
+	}
+	if len(ids) > 0 {
+		if err := flush(); err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// documentIDKey returns a comparable form of an _id of any BSON type.
+func documentIDKey(id interface{}) string {
+	raw, err := bson.Marshal(bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return fmt.Sprint(id)
+	}
+	return string(raw)
+}
+
+// documentID returns the _id of doc, or nil if it has none.
+func documentID(doc bson.D) interface{} {
+	for _, elem := range doc {
+		if elem.Key == "_id" {
+			return elem.Value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// sortableFields maps the names accepted by ?sort= to their document fields.
+var sortableFields = map[string]string{
+	"id":         "_id",
+	"name":       "name",
+	"email":      "email",
+	"department": "department",
+	"status":     "status",
+	"salary":     "salary",
+	"joinedAt":   "joinedAt",
+	"createdAt":  "createdAt",
+	"updatedAt":  "updatedAt",
+}
+
+// piiSortFields may only be sorted on by callers allowed to see them; the
+// order (and the values carried in cursors) would leak them otherwise.
+var piiSortFields = map[string]bool{
+	"email":  true,
+	"salary": true,
+}
+
+// errSortForbidden is returned when sorting by a field the caller cannot view.
+var errSortForbidden = errors.New("sorting by this field is not permitted")
+
+// sortSpec is a parsed ?sort= value.
+type sortSpec struct {
+	// Raw is the normalized parameter, used to tie cursors to their sort.
+	Raw  string
+	Keys bson.D
+}
+
+// parseSort parses a comma-separated list of fields, each optionally prefixed
+// with "-" for descending order: "-department,name" sorts by department
+// descending, then by name ascending. Fields must be in sortableFields and may
+// appear once. An empty value returns a nil spec.
+func parseSort(raw string, allowPII bool) (*sortSpec, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	spec := &sortSpec{}
+	seen := map[string]bool{}
+	var normalized []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		direction := 1
+		name := part
+		if strings.HasPrefix(part, "-") {
+			direction = -1
+			name = part[1:]
+		}
+
+		field, ok := sortableFields[name]
+		if !ok {
+			return nil, fmt.Errorf("cannot sort by '%s'", part)
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("sort field '%s' is repeated", name)
+		}
+		if piiSortFields[name] && !allowPII {
+			return nil, fmt.Errorf("%w: %s", errSortForbidden, name)
+		}
+		seen[field] = true
+		spec.Keys = append(spec.Keys, bson.E{Key: field, Value: direction})
+		normalized = append(normalized, part)
+	}
+	spec.Raw = strings.Join(normalized, ",")
+	return spec, nil
+}
+
+// withIDTiebreak returns the sort keys followed by _id ascending, unless _id is
+// already one of them, so the order is total and stable across pages.
+func (s *sortSpec) withIDTiebreak() bson.D {
+	keys := append(bson.D{}, s.Keys...)
+	for _, key := range keys {
+		if key.Key == "_id" {
+			return keys
+		}
+	}
+	return append(keys, bson.E{Key: "_id", Value: 1})
+}
+
+// sortValues returns the values of doc for the sort keys, in order. Missing
+// fields are nil.
+func (s *sortSpec) sortValues(doc bson.M) bson.A {
+	values := make(bson.A, 0, len(s.Keys))
+	for _, key := range s.withIDTiebreak() {
+		values = append(values, doc[key.Key])
+	}
+	return values
+}
+
+// afterFilter returns the keyset condition selecting the documents that sort
+// strictly after values (as returned by sortValues): for each key in turn, all
+// earlier keys are equal and this one is past the value.
+//
+// Mongo sorts null and missing fields before every other value, and range
+// operators never match them, so those cases are expanded explicitly.
+func (s *sortSpec) afterFilter(values bson.A) bson.M {
+	keys := s.withIDTiebreak()
+	branches := bson.A{}
+	equal := bson.M{}
+	for i, key := range keys {
+		value := values[i]
+		ascending := key.Value == 1
+
+		var past bson.M
+		switch {
+		case value == nil && ascending:
+			past = bson.M{key.Key: bson.M{"$ne": nil}}
+		case value == nil:
+			// Nothing sorts below null in descending order.
+		case ascending:
+			past = bson.M{key.Key: bson.M{"$gt": value}}
+		default:
+			past = bson.M{"$or": bson.A{
+				bson.M{key.Key: bson.M{"$lt": value}},
+				bson.M{key.Key: nil},
+			}}
+		}
+		if past != nil {
+			branch := bson.M{}
+			for k, v := range equal {
+				branch[k] = v
+			}
+			if len(branch) == 0 {
+				branches = append(branches, past)
+			} else {
+				branches = append(branches, bson.M{"$and": bson.A{branch, past}})
+			}
+		}
+		equal[key.Key] = value
+	}
+	return bson.M{"$or": branches}
+}
+
+// encodeSortValues and decodeSortValues carry sort values inside a cursor.
+// BSON is used so dates and numbers keep their types.
+func encodeSortValues(values bson.A) []byte {
+	data, _ := bson.Marshal(bson.D{{Key: "v", Value: values}})
+	return data
+}
+
+func decodeSortValues(data []byte) (bson.A, error) {
+	var doc struct {
+		V bson.A `bson:"v"`
+	}
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return nil, errInvalidCursor
+	}
+	return doc.V, nil
+}
+
+// parseSortParam parses ?sort= for a list handler, writing the error response
+// and returning false when it is invalid.
+func parseSortParam(w http.ResponseWriter, r *http.Request) (*sortSpec, bool) {
+	sort, err := parseSort(r.URL.Query().Get("sort"), canViewPII(r))
+	if errors.Is(err, errSortForbidden) {
+		writeError(w, http.StatusForbidden, err.Error())
+		return nil, false
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return sort, true
+}
@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// dbInfo records what ConnectToMongoDB found and set up, for the startup summary.
+type dbInfo struct {
+	Database       string
+	Collection     string
+	ServerVersion  string
+	IndexesCreated bool
+}
+
+var connInfo dbInfo
+
+// employeeIndexes are the indexes the application relies on, besides those of
+// uniqueKeyIndexes, which ensureUniqueKeyIndex builds.
+var employeeIndexes = []mongo.IndexModel{
+	{
+		Keys:    bson.D{{Key: "phoneDigits", Value: 1}},
+		Options: options.Index().SetName("phoneDigits_1"),
+	},
+	{
+		Keys:    bson.D{{Key: "nameSearch", Value: 1}},
+		Options: options.Index().SetName("nameSearch_1"),
+	},
+	{
+		Keys:    bson.D{{Key: "managerId", Value: 1}},
+		Options: options.Index().SetName("managerId_1"),
+	},
+	{
+		Keys:    bson.D{{Key: "tags", Value: 1}},
+		Options: options.Index().SetName("tags_1"),
+	},
+	{
+		Keys:    bson.D{{Key: "status", Value: 1}},
+		Options: options.Index().SetName("status_1"),
+	},
+	{
+		Keys:    bson.D{{Key: "updatedAt", Value: 1}},
+		Options: options.Index().SetName("updatedAt_1"),
+	},
+	{
+		Keys:    bson.D{{Key: "createdBy", Value: 1}},
+		Options: options.Index().SetName("createdBy_1"),
+	},
+	{
+		Keys:    bson.D{{Key: "lastSeenAt", Value: 1}},
+		Options: options.Index().SetName("lastSeenAt_1"),
+	},
+}
+
+// ensureIndexes creates the application indexes if they do not already exist.
+// Creating an existing index with the same definition is a no-op.
+func ensureIndexes(ctx context.Context) error {
+	if _, err := collection.Indexes().CreateMany(ctx, employeeIndexes); err != nil {
+		return fmt.Errorf("error creating indexes: %w", err)
+	}
+	if _, err := auditCollection.Indexes().CreateMany(ctx, auditIndexes); err != nil {
+		return fmt.Errorf("error creating audit indexes: %w", err)
+	}
+	return nil
+}
+
+// serverVersion returns the MongoDB version reported by the buildInfo command.
+func serverVersion(ctx context.Context, db *mongo.Database) (string, error) {
+	var info struct {
+		Version string `bson:"version"`
+	}
+	if err := db.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info); err != nil {
+		return "", fmt.Errorf("error running buildInfo: %w", err)
+	}
+	return info.Version, nil
+}
+
+// LogStartupSummary logs a single line describing the settings the server
+// started with, so operators can confirm the configuration at a glance.
+func LogStartupSummary(addr string, timeouts ServerTimeouts) {
+	fields := []string{
+		fmt.Sprintf("addr=%s", addr),
+		fmt.Sprintf("database=%s", connInfo.Database),
+		fmt.Sprintf("collection=%s", connInfo.Collection),
+		fmt.Sprintf("indexes_created=%t", connInfo.IndexesCreated),
+		fmt.Sprintf("mongo_version=%s", connInfo.ServerVersion),
+		fmt.Sprintf("audit_policy=%s", auditFailurePolicy()),
+		fmt.Sprintf("page_size=%d", defaultPageSize),
+		fmt.Sprintf("max_page_size=%d", maxPageSize),
+		fmt.Sprintf("field_encryption=%t", fieldEncryption != nil),
+		fmt.Sprintf("features=%s", enabledFeatureNames()),
+		fmt.Sprintf("unique_key=%s", strings.Join(uniqueKey, ",")),
+		fmt.Sprintf("timeouts=%s", timeouts),
+		fmt.Sprintf("api_prefix=%s", APIPrefix()),
+		fmt.Sprintf("ops_prefix=%s", OpsPrefix()),
+	}
+	log.Printf("startup: %s", strings.Join(fields, " "))
+}
+
+// backfillNameSearch fills in nameSearch for employees stored before it
+// existed, so that accent-insensitive search finds them too. Once every
+// document has the field this only costs an index lookup.
+func backfillNameSearch(ctx context.Context) error {
+	filter := bson.M{"nameSearch": bson.M{"$exists": false}, "name": bson.M{"$exists": true}}
+	cur, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"name": 1}))
+	if err != nil {
+		return fmt.Errorf("error backfilling nameSearch: %w", err)
+	}
+	defer closeCursor(ctx, cur)
+
+	var updates []mongo.WriteModel
+	for cur.Next(ctx) {
+		var doc struct {
+			ID   bson.ObjectID `bson:"_id"`
+			Name string        `bson:"name"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return fmt.Errorf("error backfilling nameSearch: %w", err)
+		}
+		updates = append(updates, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": doc.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"nameSearch": foldSearchText(doc.Name)}}))
+	}
+	if err := cur.Err(); err != nil {
+		return fmt.Errorf("error backfilling nameSearch: %w", err)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	if _, err := collection.BulkWrite(ctx, updates, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("error backfilling nameSearch: %w", err)
+	}
+	fmt.Printf("Backfilled nameSearch for %d employees\n", len(updates))
+	return nil
+}
+
+// backfillPhoneDigits fills in phoneDigits for employees stored before it
+// existed, so that ?phone= and /employees/by-phone find them too. Anonymized
+// employees have no real phone and are skipped.
+func backfillPhoneDigits(ctx context.Context) error {
+	filter := bson.M{
+		"phoneDigits":  bson.M{"$exists": false},
+		"phone":        bson.M{"$exists": true},
+		"anonymizedAt": bson.M{"$exists": false},
+	}
+	cur, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"phone": 1}))
+	if err != nil {
+		return fmt.Errorf("error backfilling phoneDigits: %w", err)
+	}
+	defer closeCursor(ctx, cur)
+
+	var updates []mongo.WriteModel
+	for cur.Next(ctx) {
+		var doc struct {
+			ID    bson.ObjectID `bson:"_id"`
+			Phone string        `bson:"phone"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return fmt.Errorf("error backfilling phoneDigits: %w", err)
+		}
+		// A phone that cannot be decrypted is left alone rather than indexed
+		// by the digits of its ciphertext.
+		phone := decryptValue(doc.Phone)
+		digits := phoneDigits(phone)
+		if digits == "" || strings.HasPrefix(phone, encryptedPrefix) {
+			continue
+		}
+		updates = append(updates, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": doc.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"phoneDigits": encryptPhoneDigits(digits)}}))
+	}
+	if err := cur.Err(); err != nil {
+		return fmt.Errorf("error backfilling phoneDigits: %w", err)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	if _, err := collection.BulkWrite(ctx, updates, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("error backfilling phoneDigits: %w", err)
+	}
+	fmt.Printf("Backfilled phoneDigits for %d employees\n", len(updates))
+	return nil
+}
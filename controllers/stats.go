@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// departmentSalaryStats summarizes salaries within one department. The salary
+// figures are null when no employee in the department has a salary recorded.
+type departmentSalaryStats struct {
+	Department    string   `json:"department" bson:"_id"`
+	Count         int      `json:"count" bson:"count"`
+	SalaryCount   int      `json:"salaryCount" bson:"salaryCount"`
+	AverageSalary *float64 `json:"averageSalary" bson:"averageSalary"`
+	MinSalary     *float64 `json:"minSalary" bson:"minSalary"`
+	MaxSalary     *float64 `json:"maxSalary" bson:"maxSalary"`
+}
+
+// GetSalaryStats - HTTP handler to get salary statistics per department
+func GetSalaryStats(w http.ResponseWriter, r *http.Request) {
+	// $avg/$min/$max skip missing and non-numeric salaries and yield null for a
+	// department without any, so there is no division by zero to guard against.
+	pipeline := bson.A{
+		bson.M{"$match": notDeleted},
+		bson.M{"$group": bson.M{
+			"_id":   "$department",
+			"count": bson.M{"$sum": 1},
+			"salaryCount": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$isNumber": "$salary"}, 1, 0},
+			}},
+			"averageSalary": bson.M{"$avg": "$salary"},
+			"minSalary":     bson.M{"$min": "$salary"},
+			"maxSalary":     bson.M{"$max": "$salary"},
+		}},
+		bson.M{"$sort": bson.M{"_id": 1}},
+	}
+
+	cur, err := listCollection.Aggregate(r.Context(), pipeline)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to compute salary stats: %v", err))
+		return
+	}
+	defer closeCursor(r.Context(), cur)
+
+	stats := []departmentSalaryStats{}
+	if err := cur.All(r.Context(), &stats); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to compute salary stats: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// GetStatusStats - HTTP handler to count employees by status
+//
+// Employees stored without a status count as active, the default. Every
+// status is present in the response, with zero when nobody has it.
+func GetStatusStats(w http.ResponseWriter, r *http.Request) {
+	// Projecting only status up front lets the status_1 index cover the
+	// group when the planner picks it.
+	pipeline := bson.A{
+		bson.M{"$match": notDeleted},
+		bson.M{"$project": bson.M{"_id": 0, "status": 1}},
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"$ifNull": bson.A{"$status", models.StatusActive}},
+			"count": bson.M{"$sum": 1},
+		}},
+	}
+
+	cur, err := listCollection.Aggregate(r.Context(), pipeline)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to compute status stats: %v", err))
+		return
+	}
+	defer closeCursor(r.Context(), cur)
+
+	var groups []struct {
+		Status string `bson:"_id"`
+		Count  int    `bson:"count"`
+	}
+	if err := cur.All(r.Context(), &groups); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to compute status stats: %v", err))
+		return
+	}
+
+	counts := map[string]int{}
+	for status := range validStatuses {
+		counts[status] = 0
+	}
+	total := 0
+	for _, group := range groups {
+		counts[group.Status] += group.Count
+		total += group.Count
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"total":  total,
+		"counts": counts,
+	})
+}
+
+const (
+	defaultTopDepartments = 5
+	maxTopDepartments     = 50
+)
+
+// departmentCount is the headcount of one department.
+type departmentCount struct {
+	Department string `json:"department" bson:"_id"`
+	Count      int    `json:"count" bson:"count"`
+}
+
+// GetTopDepartments - HTTP handler to list the largest departments by headcount
+//
+// ?limit= sets how many departments are returned (default 5, at most 50).
+// Departments with the same headcount are ordered by name.
+func GetTopDepartments(w http.ResponseWriter, r *http.Request) {
+	limit := defaultTopDepartments
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxTopDepartments {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxTopDepartments))
+			return
+		}
+		limit = n
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": withNotDeleted(bson.M{"department": bson.M{"$nin": bson.A{nil, ""}}})},
+		bson.M{"$group": bson.M{
+			"_id":   "$department",
+			"count": bson.M{"$sum": 1},
+		}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": limit},
+	}
+
+	cur, err := listCollection.Aggregate(r.Context(), pipeline)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to compute top departments: %v", err))
+		return
+	}
+	defer closeCursor(r.Context(), cur)
+
+	departments := []departmentCount{}
+	if err := cur.All(r.Context(), &departments); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to compute top departments: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, departments)
+}
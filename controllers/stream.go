@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// streamHeartbeat is how often an idle event stream sends a comment line, so
+// proxies and load balancers do not close the connection.
+const streamHeartbeat = 15 * time.Second
+
+// changeStreamNotSupported is the server error code for $changeStream on a
+// standalone mongod.
+const changeStreamNotSupported = 40573
+
+// Employee event types, as sent in the SSE event field.
+const (
+	eventCreated = "employee.created"
+	eventUpdated = "employee.updated"
+	eventDeleted = "employee.deleted"
+)
+
+// employeeEvent is one change to the directory, as pushed to clients.
+// Employee is the document after the change and is left out of deletes.
+type employeeEvent struct {
+	Type     string           `json:"type"`
+	ID       string           `json:"id"`
+	Employee *models.Employee `json:"employee,omitempty"`
+	// Token is the change stream resume token, sent as the SSE event id.
+	Token string `json:"-"`
+}
+
+// changeEvent is the part of a change stream document that employeeEvent needs.
+type changeEvent struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		ID bson.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument *models.Employee `bson:"fullDocument"`
+}
+
+// watchEmployees opens a change stream on the employee collection for
+// inserts, updates, replaces and deletes, resuming after resumeToken when it
+// is set. Updates carry the current document.
+func watchEmployees(ctx context.Context, resumeToken string) (*mongo.ChangeStream, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"operationType": bson.M{"$in": bson.A{"insert", "update", "replace", "delete"}}}}},
+	}
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if resumeToken != "" {
+		opts.SetResumeAfter(bson.M{"_data": resumeToken})
+	}
+	return collection.Watch(ctx, pipeline, opts)
+}
+
+// isChangeStreamUnsupported reports whether err is the server refusing a
+// change stream because it is not part of a replica set.
+func isChangeStreamUnsupported(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(changeStreamNotSupported)
+}
+
+// writeWatchError writes the response for a change stream that failed to open.
+func writeWatchError(w http.ResponseWriter, err error) {
+	if isChangeStreamUnsupported(err) {
+		writeError(w, http.StatusServiceUnavailable, "Change streams require MongoDB to run as a replica set")
+		return
+	}
+	writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to watch employees: %v", err))
+}
+
+// nextEmployeeEvent converts the current document of stream to an
+// employeeEvent. Soft deletes are updates that set deletedAt and are reported
+// as deletes.
+func nextEmployeeEvent(stream *mongo.ChangeStream) (employeeEvent, error) {
+	var change changeEvent
+	if err := stream.Decode(&change); err != nil {
+		return employeeEvent{}, fmt.Errorf("error decoding change event: %w", err)
+	}
+
+	if change.FullDocument != nil {
+		decryptEmployee(change.FullDocument)
+	}
+	event := employeeEvent{ID: change.DocumentKey.ID.Hex(), Employee: change.FullDocument}
+	if token, ok := stream.ResumeToken().Lookup("_data").StringValueOK(); ok {
+		event.Token = token
+	}
+	switch {
+	case change.OperationType == "insert":
+		event.Type = eventCreated
+	case change.OperationType == "delete" || change.FullDocument == nil || change.FullDocument.DeletedAt != nil:
+		// A nil document on update means it was removed before the lookup.
+		event.Type = eventDeleted
+		event.Employee = nil
+	default:
+		event.Type = eventUpdated
+	}
+	return event, nil
+}
+
+// StreamEmployeeEvents - HTTP handler to push employee changes as Server-Sent Events
+//
+// Every create, update and delete is sent as an event named employee.created,
+// employee.updated or employee.deleted, whose data is {"type", "id",
+// "employee"}; deletes, soft deletes included, carry no employee. PII is
+// masked as on reads. The event id is the change stream resume token, so a
+// reconnecting EventSource resumes where it left off through Last-Event-ID.
+// The change stream is closed as soon as the client disconnects.
+//
+// Change streams require MongoDB to run as a replica set; against a
+// standalone server the endpoint answers 503.
+func StreamEmployeeEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	resumeToken := r.Header.Get("Last-Event-ID")
+	stream, err := watchEmployees(ctx, resumeToken)
+	if err != nil {
+		if resumeToken != "" && !isChangeStreamUnsupported(err) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Cannot resume from Last-Event-ID: %v", err))
+			return
+		}
+		writeWatchError(w, err)
+		return
+	}
+
+	// The stream is read and closed by this goroutine; cancelling ctx, on
+	// disconnect or return, unblocks Next and ends it.
+	events := make(chan employeeEvent)
+	go func() {
+		defer close(events)
+		defer stream.Close(context.Background())
+		for stream.Next(ctx) {
+			event, err := nextEmployeeEvent(stream)
+			if err != nil {
+				log.Printf("Warning: skipping change event: %v", err)
+				continue
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			log.Printf("Warning: employee change stream ended: %v", err)
+		}
+	}()
+
+	clearWriteDeadline(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	maskPII := !canViewPII(r)
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if maskPII && event.Employee != nil {
+				masked := maskEmployee(*event.Employee)
+				event.Employee = &masked
+			}
+			if err := writeSSE(w, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeSSE writes event in the text/event-stream format.
+func writeSSE(w http.ResponseWriter, event employeeEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Warning: skipping change event %s: %v", event.ID, err)
+		return nil
+	}
+	if event.Token != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", event.Token); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// tagPattern is the form of a tag: 1-32 letters, digits, hyphens or
+// underscores. Tags are stored lowercased, so "Remote" and "remote" are one tag.
+var tagPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// validateTag implements the "tag" tag.
+func validateTag(fl validator.FieldLevel) bool {
+	return tagPattern.MatchString(fl.Field().String())
+}
+
+// normalizeTags lowercases tags and drops duplicates, keeping the first
+// occurrence of each. It returns nil for no tags so none is stored.
+func normalizeTags(tags []string) []string {
+	var normalized []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// queryTags reads tag query values, which may each hold a comma-separated list.
+func queryTags(values []string) []string {
+	var tags []string
+	for _, value := range values {
+		tags = append(tags, strings.Split(value, ",")...)
+	}
+	return normalizeTags(tags)
+}
+
+// BulkTagEmployees - HTTP handler to add and remove tags on a list of employees
+//
+// The body is {"ids": [...], "add": [...], "remove": [...]}; at least one of
+// add and remove must be non-empty, and a tag cannot be in both. Adding a tag
+// an employee already has, or removing one it lacks, is a no-op. Supports
+// ?dryRun=true.
+func BulkTagEmployees(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := parseDryRun(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req models.BulkTag
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+	if err := checkArraySizes(map[string]int{"ids": len(req.IDs), "add": len(req.Add), "remove": len(req.Remove)}); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+	add, remove := normalizeTags(req.Add), normalizeTags(req.Remove)
+	if len(add) == 0 && len(remove) == 0 {
+		writeError(w, http.StatusBadRequest, "Request body must add or remove at least one tag")
+		return
+	}
+	for _, tag := range add {
+		if contains(remove, tag) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("tag %q cannot be both added and removed", tag))
+			return
+		}
+	}
+	ids, err := parseObjectIDs(req.IDs)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := withNotDeleted(bson.M{"_id": bson.M{"$in": ids}})
+	if dryRun {
+		writeDryRun(w, r, filter)
+		return
+	}
+
+	affected, err := findAffected(r.Context(), filter, "tags")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to tag employees: %v", err))
+		return
+	}
+	filter["_id"] = bson.M{"$in": affectedIDs(affected)}
+
+	// $addToSet and $pull on the same field conflict within one update, so
+	// they are applied one after the other, each only to the employees it
+	// changes so that updatedAt is left alone on the others.
+	now := time.Now().UTC()
+	if len(add) > 0 {
+		_, err = collection.UpdateMany(r.Context(), withTagCondition(filter, bson.M{"$not": bson.M{"$all": add}}), bson.M{
+			"$addToSet": bson.M{"tags": bson.M{"$each": add}},
+			"$set":      bson.M{"updatedAt": now},
+		})
+	}
+	if err == nil && len(remove) > 0 {
+		_, err = collection.UpdateMany(r.Context(), withTagCondition(filter, bson.M{"$in": remove}), bson.M{
+			"$pull": bson.M{"tags": bson.M{"$in": remove}},
+			"$set":  bson.M{"updatedAt": now},
+		})
+	}
+	detailCache().purge()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to tag employees: %v", err))
+		return
+	}
+
+	entries := make([]auditEntry, 0, len(affected))
+	for _, doc := range affected {
+		before := documentTags(doc["tags"])
+		after := applyTagChanges(before, add, remove)
+		if reflect.DeepEqual(before, after) {
+			continue
+		}
+		entries = append(entries, newAuditEntry(r.Context(), auditUpdate, doc["_id"].(bson.ObjectID),
+			bson.M{"tags": before}, bson.M{"tags": after}))
+	}
+	fmt.Printf("Retagged %d employees\n", len(entries))
+	if err := recordAuditMany(r.Context(), entries); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to tag employees: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":  "Employees tagged successfully",
+		"matched":  len(affected),
+		"modified": len(entries),
+	})
+}
+
+// withTagCondition returns a copy of filter that also requires condition on tags.
+func withTagCondition(filter bson.M, condition bson.M) bson.M {
+	combined := bson.M{"tags": condition}
+	for key, value := range filter {
+		combined[key] = value
+	}
+	return combined
+}
+
+// documentTags converts the stored tags of a raw document to strings.
+func documentTags(value interface{}) []string {
+	array, _ := value.(bson.A)
+	tags := make([]string, 0, len(array))
+	for _, tag := range array {
+		if s, ok := tag.(string); ok {
+			tags = append(tags, s)
+		}
+	}
+	return tags
+}
+
+// applyTagChanges mirrors the $addToSet and $pull of BulkTagEmployees.
+func applyTagChanges(tags, add, remove []string) []string {
+	result := make([]string, 0, len(tags)+len(add))
+	for _, tag := range tags {
+		if !contains(remove, tag) {
+			result = append(result, tag)
+		}
+	}
+	for _, tag := range add {
+		if !contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// TouchEmployee - HTTP handler to record that an employee was just active
+//
+// Sets lastSeenAt to now and nothing else: updatedAt is not bumped and no
+// audit entry is written, so activity pings do not show up as edits. Dormant
+// accounts are found with ?inactiveSince= on the list endpoints.
+func TouchEmployee(w http.ResponseWriter, r *http.Request) {
+	employeeID := employeeIDFromRequest(r)
+
+	seenAt, err := touchEmployee(r.Context(), employeeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errEmployeeNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeServerError(w, "Failed to update last seen time", err)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":    "Employee last seen time updated successfully",
+		"lastSeenAt": seenAt,
+	})
+}
+
+// touchEmployee sets the lastSeenAt of an employee to now with a single-field
+// $set and returns the time it recorded.
+func touchEmployee(ctx context.Context, id bson.ObjectID) (time.Time, error) {
+	if err := checkDatabase(); err != nil {
+		return time.Time{}, err
+	}
+
+	now := time.Now().UTC()
+	result, err := collection.UpdateOne(ctx, withNotDeleted(bson.M{"_id": id}), bson.M{"$set": bson.M{"lastSeenAt": now}})
+	if err != nil {
+		return now, fmt.Errorf("error updating last seen time: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return now, fmt.Errorf("%w: %s", errEmployeeNotFound, id.Hex())
+	}
+	detailCache().invalidate(id.Hex())
+	return now, nil
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"net/http"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// maxTypeaheadResults caps the suggestions returned for one keystroke.
+const maxTypeaheadResults = 10
+
+// typeaheadResult is the minimal shape returned by GetTypeahead.
+type typeaheadResult struct {
+	ID         bson.ObjectID `json:"id" bson:"_id"`
+	Name       string        `json:"name" bson:"name"`
+	Department string        `json:"department,omitempty" bson:"department"`
+}
+
+// GetTypeahead - HTTP handler to suggest employees as the user types a name
+//
+// ?q= is matched against names as by /employees/search, ignoring case and
+// accents. At most 10 {id, name, department} results are returned, names
+// starting with q first, then names with a word starting with q, then any
+// other match, alphabetically within each group.
+func GetTypeahead(w http.ResponseWriter, r *http.Request) {
+	q := foldSearchText(r.URL.Query().Get("q"))
+	if q == "" {
+		writeError(w, http.StatusBadRequest, "Query parameter 'q' is required")
+		return
+	}
+	literal := regexp.QuoteMeta(q)
+
+	pipeline := bson.A{
+		bson.M{"$match": withNotDeleted(bson.M{"nameSearch": foldedSearchPattern(q)})},
+		bson.M{"$project": bson.M{
+			"name":       1,
+			"department": 1,
+			"nameSearch": 1,
+			"rank": bson.M{"$switch": bson.M{
+				"branches": bson.A{
+					bson.M{"case": bson.M{"$regexMatch": bson.M{"input": "$nameSearch", "regex": "^" + literal}}, "then": 0},
+					bson.M{"case": bson.M{"$regexMatch": bson.M{"input": "$nameSearch", "regex": " " + literal}}, "then": 1},
+				},
+				"default": 2,
+			}},
+		}},
+		bson.M{"$sort": bson.D{{Key: "rank", Value: 1}, {Key: "nameSearch", Value: 1}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": maxTypeaheadResults},
+	}
+
+	if err := checkDatabase(); err != nil {
+		writeServerError(w, "Failed to search employees", err)
+		return
+	}
+
+	cur, err := listCollection.Aggregate(r.Context(), pipeline)
+	if err != nil {
+		writeServerError(w, "Failed to search employees", err)
+		return
+	}
+	defer closeCursor(r.Context(), cur)
+
+	results := []typeaheadResult{}
+	if err := cur.All(r.Context(), &results); err != nil {
+		writeServerError(w, "Failed to search employees", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
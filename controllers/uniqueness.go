@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// errDuplicateKey marks a write rejected by the unique index; see
+// duplicateKeyMessage for the text shown to clients.
+var errDuplicateKey = errors.New("duplicate unique key")
+
+// uniqueKeyFields are the fields UNIQUE_KEY may combine. Phones are left out:
+// their stored form is not unique once anonymized.
+var uniqueKeyFields = map[string]bool{"name": true, "email": true, "department": true}
+
+// uniqueKey is the combination of fields no two employees may share, set
+// from UNIQUE_KEY by ConnectToMongoDB.
+var uniqueKey = []string{"email"}
+
+// uniqueKeyFromEnv reads UNIQUE_KEY, a comma-separated list of fields from
+// uniqueKeyFields, e.g. "name,department"; the default is "email".
+//
+// The unique index is built by ensureUniqueKeyIndex and named after the
+// fields, so "email" keeps the email_unique index of earlier versions.
+// Changing the policy drops the index of the previous one on the next start.
+// Soft-deleted employees still hold their key.
+func uniqueKeyFromEnv() ([]string, error) {
+	raw := strings.TrimSpace(os.Getenv("UNIQUE_KEY"))
+	if raw == "" {
+		return []string{"email"}, nil
+	}
+	var fields []string
+	seen := map[string]bool{}
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if !uniqueKeyFields[field] {
+			return nil, fmt.Errorf("UNIQUE_KEY: unknown field %q; must be name, email or department", field)
+		}
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// uniqueKeyIndexName is the name of the unique index enforcing uniqueKey.
+func uniqueKeyIndexName() string {
+	return strings.Join(uniqueKey, "_") + "_unique"
+}
+
+// uniqueKeyIndexes returns the unique index enforcing uniqueKey, and a plain
+// email index when the key leaves email out, for the lookups by email. The
+// result maps each index name to its model.
+func uniqueKeyIndexes() map[string]mongo.IndexModel {
+	keys := bson.D{}
+	for _, field := range uniqueKey {
+		keys = append(keys, bson.E{Key: field, Value: 1})
+	}
+	indexes := map[string]mongo.IndexModel{
+		uniqueKeyIndexName(): {
+			Keys:    keys,
+			Options: options.Index().SetName(uniqueKeyIndexName()).SetUnique(true),
+		},
+	}
+	if !contains(uniqueKey, "email") {
+		indexes["email_1"] = mongo.IndexModel{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetName("email_1"),
+		}
+	}
+	return indexes
+}
+
+// ensureUniqueKeyIndex builds the indexes of uniqueKeyIndexes, replacing those
+// left by a previous UNIQUE_KEY: indexes on the same keys under another name
+// are dropped first, as they would block the build, and other *_unique
+// indexes once the new unique index exists.
+func ensureUniqueKeyIndex(ctx context.Context) error {
+	wanted := uniqueKeyIndexes()
+	wantedKeys := map[string]string{}
+	toCreate := make([]mongo.IndexModel, 0, len(wanted))
+	for name, model := range wanted {
+		wantedKeys[indexKeySpec(model.Keys.(bson.D))] = name
+		toCreate = append(toCreate, model)
+	}
+
+	existing, err := listIndexes(ctx)
+	if err != nil {
+		return err
+	}
+	var stale []string
+	for _, index := range existing {
+		if _, ok := wanted[index.Name]; ok {
+			continue
+		}
+		if _, ok := wantedKeys[strings.Join(index.Keys, ",")]; ok {
+			if err := dropIndex(ctx, index.Name); err != nil {
+				return err
+			}
+		} else if strings.HasSuffix(index.Name, "_unique") {
+			stale = append(stale, index.Name)
+		}
+	}
+
+	if _, err := collection.Indexes().CreateMany(ctx, toCreate); err != nil {
+		return fmt.Errorf("error creating unique key index %s: %w", uniqueKeyIndexName(), err)
+	}
+	for _, name := range stale {
+		if err := dropIndex(ctx, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexKeySpec formats index keys as listIndexes does, e.g. "email:1".
+func indexKeySpec(keys bson.D) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s:%v", key.Key, key.Value)
+	}
+	return strings.Join(parts, ",")
+}
+
+// dropIndex drops the named index of the employee collection.
+func dropIndex(ctx context.Context, name string) error {
+	if err := collection.Indexes().DropOne(ctx, name); err != nil {
+		return fmt.Errorf("error dropping index %s: %w", name, err)
+	}
+	log.Printf("Dropped index %s left by a previous UNIQUE_KEY", name)
+	return nil
+}
+
+// duplicateKeyMessage is the 409 message for a write rejected by the unique
+// index, e.g. "an employee with this name and department already exists".
+func duplicateKeyMessage() string {
+	fields := strings.Join(uniqueKey, ", ")
+	if n := len(uniqueKey); n > 1 {
+		fields = strings.Join(uniqueKey[:n-1], ", ") + " and " + uniqueKey[n-1]
+	}
+	return fmt.Sprintf("an employee with this %s already exists", fields)
+}
+
+// uniqueKeyOf returns the unique key of employee as one comparable string.
+func uniqueKeyOf(employee models.Employee) string {
+	values := make([]string, len(uniqueKey))
+	for i, field := range uniqueKey {
+		values[i] = uniqueKeyValue(employee, field)
+	}
+	return strings.Join(values, "\x00")
+}
+
+// uniqueKeyValue returns the plaintext value of one field of the unique key.
+func uniqueKeyValue(employee models.Employee, field string) string {
+	switch field {
+	case "name":
+		return employee.Name
+	case "department":
+		return employee.Department
+	default:
+		return employee.Email
+	}
+}
+
+// uniqueKeyCondition matches the stored employees with the same unique key as
+// employee, whether their email is stored encrypted or not.
+func uniqueKeyCondition(employee models.Employee) bson.M {
+	condition := bson.M{}
+	for _, field := range uniqueKey {
+		if field == "email" {
+			condition["email"] = bson.M{"$in": storedEmails(employee.Email)}
+			continue
+		}
+		condition[field] = uniqueKeyValue(employee, field)
+	}
+	return condition
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// partialUpdates reports whether PUT bodies are validated leniently.
+// UPDATE_VALIDATION=partial lets clients send only the fields they change,
+// each validated when present; the default, strict, applies the create rules.
+func partialUpdates() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv("UPDATE_VALIDATION")), "partial")
+}
+
+// decodeEmployeeUpdate reads and validates a PUT body according to
+// UPDATE_VALIDATION, including the VALIDATE_EMAIL_MX check of the email. It
+// writes the error response and returns false when the body is rejected.
+func decodeEmployeeUpdate(w http.ResponseWriter, r *http.Request) (models.Employee, bool) {
+	if !partialUpdates() {
+		var employee models.Employee
+		if err := json.NewDecoder(r.Body).Decode(&employee); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+			return employee, false
+		}
+		normalizeEmployee(&employee)
+		if err := validate.Struct(employee); err != nil {
+			writeValidationError(w, err)
+			return employee, false
+		}
+		return employee, emailDomainAccepted(w, r, employee.Email)
+	}
+
+	var update models.EmployeeUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return models.Employee{}, false
+	}
+	if reflect.ValueOf(update).IsZero() {
+		writeError(w, http.StatusBadRequest, "Request body must set at least one field")
+		return models.Employee{}, false
+	}
+	normalizeContact(&update.Email, &update.Phone)
+	if err := validate.Struct(update); err != nil {
+		writeValidationError(w, err)
+		return models.Employee{}, false
+	}
+	if !emailDomainAccepted(w, r, update.Email) {
+		return models.Employee{}, false
+	}
+	return update.Employee(), true
+}
+
+// writableFields allowlists, by BSON name, the fields a PUT may set. The
+// derived fields are filled in by updateOneEmployee itself; anything else the
+// body carries, such as createdAt, deletedAt or mergedInto, is dropped so
+// clients cannot write server-managed state.
+var writableFields = map[string]bool{
+	"name":            true,
+	"email":           true,
+	"phone":           true,
+	"department":      true,
+	"address":         true,
+	"salary":          true,
+	"managerId":       true,
+	"status":          true,
+	"joinedAt":        true,
+	"terminationDate": true,
+	"tags":            true,
+
+	"updatedAt":   true,
+	"phoneDigits": true,
+	"nameSearch":  true,
+}
+
+// writableSet returns the $set document for employee, holding only the
+// non-empty fields in writableFields.
+func writableSet(employee models.Employee) (bson.M, error) {
+	doc, err := toDocument(employee)
+	if err != nil {
+		return nil, err
+	}
+	for field := range doc {
+		if !writableFields[field] {
+			delete(doc, field)
+		}
+	}
+	return doc, nil
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sangwan491/backend-assignments/employee-management/backend/models"
+)
+
+// validationProblem describes one stored employee that fails validation.
+type validationProblem struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// ValidateAllEmployees - HTTP handler to scan the collection for malformed employee documents
+//
+// Every non-deleted document is decoded and run through the same validator used
+// by create/update, so the report lists exactly the records that would be
+// rejected if they were submitted again.
+func ValidateAllEmployees(w http.ResponseWriter, r *http.Request) {
+	cur, err := listCollection.Find(r.Context(), notDeleted)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to scan employees: %v", err))
+		return
+	}
+	defer closeCursor(r.Context(), cur)
+
+	scanned := 0
+	problems := []validationProblem{}
+	for cur.Next(r.Context()) {
+		scanned++
+
+		var employee models.Employee
+		if err := cur.Decode(&employee); err != nil {
+			id, _ := cur.Current.Lookup("_id").ObjectIDOK()
+			problems = append(problems, validationProblem{ID: id.Hex(), Error: fmt.Sprintf("Cannot decode document: %v", err)})
+			continue
+		}
+		decryptEmployee(&employee)
+
+		if err := validate.Struct(employee); err != nil {
+			msg := fmt.Sprintf("Validation error: %v", err)
+			if validationErrors, ok := err.(validator.ValidationErrors); ok {
+				msg = formatValidationErrors(validationErrors)
+			}
+			problems = append(problems, validationProblem{ID: employee.ID.Hex(), Error: msg})
+		}
+	}
+	if err := cur.Err(); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to scan employees: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"scanned":  scanned,
+		"invalid":  len(problems),
+		"problems": problems,
+	})
+}
@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// WebSocket keepalive: clients must answer a ping within pongWait, and every
+// write must complete within writeWait.
+const (
+	writeWait  = 10 * time.Second
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+)
+
+// hubClientBuffer is how many events a WebSocket client may fall behind
+// before it is disconnected.
+const hubClientBuffer = 64
+
+// eventHub fans one shared change stream out to every WebSocket client. The
+// stream is opened by the first subscriber and closed when the last one
+// leaves.
+type eventHub struct {
+	mu      sync.Mutex
+	clients map[chan employeeEvent]bool
+	cancel  context.CancelFunc
+}
+
+var employeeHub = &eventHub{clients: map[chan employeeEvent]bool{}}
+
+// subscribe registers a client and returns the channel its events arrive on.
+// The channel is closed when the client falls too far behind or the change
+// stream ends.
+func (h *eventHub) subscribe() (chan employeeEvent, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cancel == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := watchEmployees(ctx, "")
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		h.cancel = cancel
+		go h.run(ctx, stream)
+	}
+
+	events := make(chan employeeEvent, hubClientBuffer)
+	h.clients[events] = true
+	return events, nil
+}
+
+// unsubscribe removes a client, closing the change stream if it was the last.
+func (h *eventHub) unsubscribe(events chan employeeEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.clients[events] {
+		delete(h.clients, events)
+		close(events)
+	}
+	if len(h.clients) == 0 && h.cancel != nil {
+		h.cancel()
+		h.cancel = nil
+	}
+}
+
+// run reads the change stream and broadcasts its events until ctx is
+// cancelled. If the stream fails, every client is disconnected so that they
+// reconnect and the next subscriber opens a new stream.
+func (h *eventHub) run(ctx context.Context, stream *mongo.ChangeStream) {
+	defer stream.Close(context.Background())
+	for stream.Next(ctx) {
+		event, err := nextEmployeeEvent(stream)
+		if err != nil {
+			log.Printf("Warning: skipping change event: %v", err)
+			continue
+		}
+		h.broadcast(event)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// Checked under the lock: once the last client has left, a new subscriber
+	// may already have opened the next stream.
+	if ctx.Err() != nil {
+		return
+	}
+	log.Printf("Warning: employee change stream ended: %v", stream.Err())
+	for events := range h.clients {
+		delete(h.clients, events)
+		close(events)
+	}
+	h.cancel()
+	h.cancel = nil
+}
+
+// broadcast sends event to every client without blocking; clients whose
+// buffer is full are dropped.
+func (h *eventHub) broadcast(event employeeEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for events := range h.clients {
+		select {
+		case events <- event:
+		default:
+			log.Printf("Warning: disconnecting slow WebSocket client")
+			delete(h.clients, events)
+			close(events)
+		}
+	}
+}
+
+// upgrader accepts any origin, in line with the CORS policy of the API.
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// ServeWebSocket - HTTP handler to broadcast employee changes over a WebSocket
+//
+// Every create, update and delete is sent as a JSON text message, {"type",
+// "id", "employee"}, shaped like the events of /api/employees/stream. All
+// connections share one change stream. The server pings every pingPeriod and
+// drops clients that stop answering or fall too far behind; messages from
+// clients are ignored. Like the SSE stream it needs a replica set and answers
+// 503 against a standalone server.
+func ServeWebSocket(w http.ResponseWriter, r *http.Request) {
+	events, err := employeeHub.subscribe()
+	if err != nil {
+		writeWatchError(w, err)
+		return
+	}
+	defer employeeHub.unsubscribe(events)
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already written the error response.
+		return
+	}
+	defer conn.Close()
+
+	// Reading is needed to process pongs and the client's close frame.
+	conn.SetReadLimit(512)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	maskPII := !canViewPII(r)
+	ping := time.NewTicker(pingPeriod)
+	defer ping.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+				return
+			}
+			if maskPII && event.Employee != nil {
+				masked := maskEmployee(*event.Employee)
+				event.Employee = &masked
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
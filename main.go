@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,15 +13,37 @@ import (
 )
 
 func main() {
+	seedCount := flag.Int("seed", 0, "insert this many generated employees and exit")
+	seedValue := flag.Uint64("seed-value", 1, "random seed for -seed; the same value generates the same employees")
+	force := flag.Bool("force", false, "with -seed, insert even if the collection already has employees")
+	flag.Parse()
+
 	// Connect to MongoDB first
 	if err := controllers.ConnectToMongoDB(); err != nil {
 		log.Fatalf("Failed to connect to MongoDB: %v", err)
 		os.Exit(1)
 	}
 
-	r := router.SetupRouter()
+	if *seedCount > 0 {
+		if _, err := controllers.SeedEmployees(context.Background(), *seedCount, *seedValue, *force); err != nil {
+			log.Fatalf("Failed to seed employees: %v", err)
+		}
+		return
+	}
+
+	const addr = ":8080"
+	timeouts := controllers.ServerTimeoutsFromEnv()
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           router.SetupRouter(),
+		ReadTimeout:       timeouts.Read,
+		ReadHeaderTimeout: timeouts.ReadHeader,
+		WriteTimeout:      timeouts.Write,
+		IdleTimeout:       timeouts.Idle,
+	}
+	controllers.LogStartupSummary(addr, timeouts)
 	fmt.Println("Server started on port 8080")
-	log.Fatal(http.ListenAndServe(":8080", r))
+	log.Fatal(server.ListenAndServe())
 	// This line will never be executed due to log.Fatal above
 	// fmt.Println("Server started on port 8080")
 }
@@ -1,13 +1,149 @@
 package models
 
 import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+// Employee lifecycle statuses.
+const (
+	StatusActive     = "active"
+	StatusOnLeave    = "on_leave"
+	StatusTerminated = "terminated"
+)
+
 type Employee struct {
-	ID         bson.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	Name       string        `json:"name,omitempty" bson:"name,omitempty" validate:"required"`
-	Email      string        `json:"email,omitempty" bson:"email,omitempty" validate:"required,email"`
-	Phone      string        `json:"phone,omitempty" bson:"phone,omitempty" validate:"required"`
-	Department string        `json:"department,omitempty" bson:"department,omitempty" validate:"required"`
+	XMLName         xml.Name       `json:"-" bson:"-" xml:"employee"`
+	ID              bson.ObjectID  `json:"id,omitzero" xml:"id,omitempty" bson:"_id,omitempty"`
+	Name            string         `json:"name,omitempty" xml:"name,omitempty" bson:"name,omitempty" validate:"required"`
+	NameSearch      string         `json:"-" xml:"-" bson:"nameSearch,omitempty"`
+	Email           string         `json:"email,omitempty" xml:"email,omitempty" bson:"email,omitempty" validate:"required,email"`
+	Phone           string         `json:"phone,omitempty" xml:"phone,omitempty" bson:"phone,omitempty" validate:"required"`
+	PhoneDigits     string         `json:"-" xml:"-" bson:"phoneDigits,omitempty"`
+	Department      string         `json:"department,omitempty" xml:"department,omitempty" bson:"department,omitempty" validate:"required,department"`
+	Address         *Address       `json:"address,omitempty" xml:"address,omitempty" bson:"address,omitempty" validate:"omitempty"`
+	Salary          *float64       `json:"salary,omitempty" xml:"salary,omitempty" bson:"salary,omitempty" validate:"omitempty,gte=0"`
+	ManagerID       *bson.ObjectID `json:"managerId,omitempty" xml:"managerId,omitempty" bson:"managerId,omitempty"`
+	Status          string         `json:"status,omitempty" xml:"status,omitempty" bson:"status,omitempty" validate:"omitempty,oneof=active on_leave terminated"`
+	JoinedAt        *time.Time     `json:"joinedAt,omitempty" xml:"joinedAt,omitempty" bson:"joinedAt,omitempty" validate:"omitempty,daterange"`
+	TerminationDate *time.Time     `json:"terminationDate,omitempty" xml:"terminationDate,omitempty" bson:"terminationDate,omitempty" validate:"omitempty,daterange"`
+	Tags            []string       `json:"tags,omitempty" xml:"tag,omitempty" bson:"tags,omitempty" validate:"omitempty,max=20,dive,tag"`
+	CreatedAt       time.Time      `json:"createdAt,omitzero" xml:"createdAt,omitempty" bson:"createdAt,omitempty"`
+	CreatedBy       string         `json:"createdBy,omitempty" xml:"createdBy,omitempty" bson:"createdBy,omitempty"`
+	UpdatedAt       time.Time      `json:"updatedAt,omitzero" xml:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
+	LastSeenAt      *time.Time     `json:"lastSeenAt,omitempty" xml:"lastSeenAt,omitempty" bson:"lastSeenAt,omitempty"`
+	DeletedAt       *time.Time     `json:"deletedAt,omitempty" xml:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+	DeletedBy       string         `json:"deletedBy,omitempty" xml:"deletedBy,omitempty" bson:"deletedBy,omitempty"`
+	DeletionReason  string         `json:"deletionReason,omitempty" xml:"deletionReason,omitempty" bson:"deletionReason,omitempty"`
+	MergedInto      *bson.ObjectID `json:"mergedInto,omitempty" xml:"mergedInto,omitempty" bson:"mergedInto,omitempty"`
+	AnonymizedAt    *time.Time     `json:"anonymizedAt,omitempty" xml:"anonymizedAt,omitempty" bson:"anonymizedAt,omitempty"`
+}
+
+// Address is an employee's mailing address, stored as an embedded sub-document.
+type Address struct {
+	Street     string `json:"street,omitempty" xml:"street,omitempty" bson:"street,omitempty"`
+	City       string `json:"city,omitempty" xml:"city,omitempty" bson:"city,omitempty"`
+	State      string `json:"state,omitempty" xml:"state,omitempty" bson:"state,omitempty"`
+	PostalCode string `json:"postalCode,omitempty" xml:"postalCode,omitempty" bson:"postalCode,omitempty" validate:"omitempty,postalcode"`
+	Country    string `json:"country,omitempty" xml:"country,omitempty" bson:"country,omitempty" validate:"required,iso3166_1_alpha2"`
+}
+
+// ManagerMove is the payload for moving an employee under a new manager.
+type ManagerMove struct {
+	ManagerID *bson.ObjectID `json:"managerId" validate:"required"`
+}
+
+// StatusUpdate is the payload for changing only an employee's lifecycle status.
+type StatusUpdate struct {
+	Status          string     `json:"status" validate:"required,oneof=active on_leave terminated"`
+	TerminationDate *time.Time `json:"terminationDate,omitempty" validate:"omitempty,daterange"`
+}
+
+// DeleteRequest is the optional body of a delete, recording why it happened.
+type DeleteRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// MergeRequest identifies two duplicate employee records to merge. The secondary
+// record is folded into the primary and then soft-deleted.
+type MergeRequest struct {
+	Primary   string `json:"primary" validate:"required"`
+	Secondary string `json:"secondary" validate:"required"`
+}
+
+// BulkDepartmentUpdate moves the listed employees to one department.
+type BulkDepartmentUpdate struct {
+	IDs        []string `json:"ids" validate:"required,min=1"`
+	Department string   `json:"department" validate:"required,department"`
+}
+
+// BatchDelete soft-deletes the listed employees, recording an optional reason.
+type BatchDelete struct {
+	IDs    []string `json:"ids" validate:"required,min=1"`
+	Reason string   `json:"reason" validate:"max=500"`
+}
+
+// BulkOffboard soft-deletes the listed employees and detaches their reports.
+type BulkOffboard struct {
+	IDs    []string `json:"ids" validate:"required,min=1"`
+	Reason string   `json:"reason" validate:"max=500"`
+}
+
+// BatchOperation is one step of a mixed batch: "create" takes data, "update"
+// takes id and data, and "delete" takes id and an optional reason. Data is
+// decoded according to op.
+type BatchOperation struct {
+	Op     string          `json:"op" validate:"required,oneof=create update delete"`
+	ID     string          `json:"id,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty"`
+	Reason string          `json:"reason,omitempty" validate:"max=500"`
+}
+
+// BulkTag adds and removes tags on the listed employees.
+type BulkTag struct {
+	IDs    []string `json:"ids" validate:"required,min=1"`
+	Add    []string `json:"add" validate:"omitempty,dive,tag"`
+	Remove []string `json:"remove" validate:"omitempty,dive,tag"`
+}
+
+// DepartmentRename moves every employee of one department to another.
+type DepartmentRename struct {
+	From string `json:"from" validate:"required"`
+	To   string `json:"to" validate:"required,department"`
+}
+
+// EmployeeUpdate is the PUT payload when UPDATE_VALIDATION=partial. Every field
+// is optional but validated when present; absent fields keep their stored values.
+type EmployeeUpdate struct {
+	Name            string         `json:"name,omitempty"`
+	Email           string         `json:"email,omitempty" validate:"omitempty,email"`
+	Phone           string         `json:"phone,omitempty"`
+	Department      string         `json:"department,omitempty" validate:"omitempty,department"`
+	Address         *Address       `json:"address,omitempty" validate:"omitempty"`
+	Salary          *float64       `json:"salary,omitempty" validate:"omitempty,gte=0"`
+	ManagerID       *bson.ObjectID `json:"managerId,omitempty"`
+	Status          string         `json:"status,omitempty" validate:"omitempty,oneof=active on_leave terminated"`
+	JoinedAt        *time.Time     `json:"joinedAt,omitempty" validate:"omitempty,daterange"`
+	TerminationDate *time.Time     `json:"terminationDate,omitempty" validate:"omitempty,daterange"`
+	Tags            []string       `json:"tags,omitempty" validate:"omitempty,max=20,dive,tag"`
+}
+
+// Employee returns the update as an Employee holding only the fields present.
+func (u EmployeeUpdate) Employee() Employee {
+	return Employee{
+		Name:            u.Name,
+		Email:           u.Email,
+		Phone:           u.Phone,
+		Department:      u.Department,
+		Address:         u.Address,
+		Salary:          u.Salary,
+		ManagerID:       u.ManagerID,
+		Status:          u.Status,
+		JoinedAt:        u.JoinedAt,
+		TerminationDate: u.TerminationDate,
+		Tags:            u.Tags,
+	}
 }
@@ -1,7 +1,10 @@
 package router
 
 import (
+	"expvar"
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
@@ -9,21 +12,269 @@ import (
 )
 
 // SetupRouter initializes all the routes for the application
+//
+// Paths match with or without a trailing slash; see ignoreTrailingSlash.
+// Optional features turned off with DISABLED_FEATURES are not registered and
+// answer 404; see controllers.EnabledFeatures. The API is mounted under
+// controllers.APIPrefix, /api by default, and the health and metrics
+// endpoints under controllers.OpsPrefix.
 func SetupRouter() http.Handler {
 	router := mux.NewRouter()
 
 	// API routes
-	api := router.PathPrefix("/api").Subrouter()
+	api := router.PathPrefix(controllers.APIPrefix()).Subrouter()
+	ops := router.PathPrefix(controllers.OpsPrefix()).Subrouter()
+
+	// Routes of disabled features go to these routers instead, which are
+	// only used to answer their paths with 404.
+	disabled := mux.NewRouter()
+	disabledAPI := disabled.PathPrefix(controllers.APIPrefix()).Subrouter()
+	disabledOps := disabled.PathPrefix(controllers.OpsPrefix()).Subrouter()
+	features := controllers.EnabledFeatures()
+	feature := func(name string, enabled, off *mux.Router) *mux.Router {
+		if features[name] {
+			return enabled
+		}
+		return off
+	}
+	admin := feature("admin", api, disabledAPI)
+	bulk := feature("bulk", api, disabledAPI)
+	export := feature("export", api, disabledAPI)
+	imports := feature("import", api, disabledAPI)
+	stats := feature("stats", api, disabledAPI)
+	stream := feature("stream", api, disabledAPI)
+
+	// produces records the media types of routes that don't respond with JSON.
+	produces := map[*mux.Route][]string{}
 
 	// Employee routes
-	api.HandleFunc("/employees", controllers.GetAllEmployees).Methods("GET")
+	listEmployees := api.HandleFunc("/employees", controllers.GetAllEmployees).Methods("GET", "HEAD")
+	produces[listEmployees] = []string{"application/json", "application/xml"}
+	importNDJSON := imports.HandleFunc("/employees", controllers.ImportEmployeesNDJSON).Methods("POST").
+		HeadersRegexp("Content-Type", "^application/x-ndjson")
+	produces[importNDJSON] = []string{"application/x-ndjson"}
 	api.HandleFunc("/employees", controllers.CreateEmployee).Methods("POST")
+	eventStream := stream.HandleFunc("/employees/stream", controllers.StreamEmployeeEvents).Methods("GET")
+	produces[eventStream] = []string{"text/event-stream"}
+	employeesHTML := api.HandleFunc("/employees.html", controllers.GetEmployeesHTML).Methods("GET")
+	produces[employeesHTML] = []string{"text/html"}
+	api.HandleFunc("/employees/search", controllers.SearchEmployees).Methods("GET")
+	api.HandleFunc("/employees/typeahead", controllers.GetTypeahead).Methods("GET")
+	api.HandleFunc("/employees/new", controllers.GetNewHires).Methods("GET")
+	api.HandleFunc("/employees/anniversaries", controllers.GetAnniversaries).Methods("GET")
+	api.HandleFunc("/employees/schema", controllers.GetEmployeeSchema).Methods("GET", "HEAD")
+	jsonSchema := api.HandleFunc("/employees/jsonschema", controllers.GetEmployeeJSONSchema).Methods("GET", "HEAD")
+	produces[jsonSchema] = []string{"application/schema+json", "application/json"}
+	exportCSV := export.HandleFunc("/employees/export.csv", controllers.ExportEmployeesCSV).Methods("GET")
+	produces[exportCSV] = []string{"text/csv"}
+	exportJSONL := export.HandleFunc("/employees/export.jsonl", controllers.ExportEmployeesJSONL).Methods("GET")
+	produces[exportJSONL] = []string{"application/x-ndjson"}
+	exportZIP := export.HandleFunc("/employees/export/by-department.zip", controllers.ExportDepartmentsZIP).Methods("GET")
+	produces[exportZIP] = []string{"application/zip"}
+	admin.HandleFunc("/employees/validate-all", controllers.RequireRole(controllers.ValidateAllEmployees, controllers.RoleAdmin)).Methods("GET")
+	stats.HandleFunc("/employees/salary-stats", controllers.RequireRole(controllers.GetSalaryStats, controllers.RoleAdmin, controllers.RoleFinance)).Methods("GET")
+	stats.HandleFunc("/employees/status-stats", controllers.GetStatusStats).Methods("GET")
+	stats.HandleFunc("/employees/top-departments", controllers.GetTopDepartments).Methods("GET")
+	api.HandleFunc("/employees/etag", controllers.GetDirectoryChecksum).Methods("GET", "HEAD")
+	api.HandleFunc("/employees/incomplete", controllers.GetIncompleteEmployees).Methods("GET")
+	api.HandleFunc("/employees/email-available", controllers.CheckEmailAvailable).Methods("GET")
+	api.HandleFunc("/employees/by-phone", controllers.RequireRole(controllers.GetEmployeeByPhone, controllers.RoleAdmin)).Methods("GET")
+	bulk.HandleFunc("/employees/bulk-update-department", controllers.RequireRole(controllers.BulkUpdateDepartment, controllers.RoleAdmin)).Methods("POST")
+	bulk.HandleFunc("/employees/bulk-tag", controllers.RequireRole(controllers.BulkTagEmployees, controllers.RoleAdmin)).Methods("POST")
+	bulk.HandleFunc("/employees/bulk-offboard", controllers.RequireRole(controllers.BulkOffboardEmployees, controllers.RoleAdmin)).Methods("POST")
+	bulk.HandleFunc("/employees/batch-delete", controllers.RequireRole(controllers.BatchDeleteEmployees, controllers.RoleAdmin)).Methods("POST")
+	bulk.HandleFunc("/employees/batch", controllers.RequireRole(controllers.BatchEmployees, controllers.RoleAdmin)).Methods("POST")
+	api.HandleFunc("/employees/merge", controllers.RequireRole(controllers.MergeEmployees, controllers.RoleAdmin)).Methods("POST")
+	getEmployee := api.HandleFunc("/employees/{id}", controllers.GetEmployee).Methods("GET", "HEAD")
+	produces[getEmployee] = []string{"application/json", "application/xml"}
 	api.HandleFunc("/employees/{id}", controllers.UpdateEmployee).Methods("PUT")
+	api.HandleFunc("/employees/{id}", controllers.PatchEmployee).Methods("PATCH")
 	api.HandleFunc("/employees/{id}", controllers.DeleteEmployee).Methods("DELETE")
+	api.HandleFunc("/employees/{id}/status", controllers.UpdateEmployeeStatus).Methods("POST")
+	api.HandleFunc("/employees/{id}/chain", controllers.GetReportingChain).Methods("GET")
+	api.HandleFunc("/employees/{id}/reports", controllers.GetDirectReports).Methods("GET")
+	api.HandleFunc("/employees/{id}/move", controllers.RequireRole(controllers.MoveEmployee, controllers.RoleAdmin)).Methods("POST")
+	api.HandleFunc("/employees/{id}/touch", controllers.RequireRole(controllers.TouchEmployee, controllers.RoleAdmin)).Methods("POST")
+	api.HandleFunc("/employees/{id}/history", controllers.RequireRole(controllers.GetEmployeeHistory, controllers.RoleAdmin)).Methods("GET")
+	api.HandleFunc("/employees/{id}/diff", controllers.RequireRole(controllers.GetEmployeeDiff, controllers.RoleAdmin)).Methods("GET")
+	api.HandleFunc("/employees/{id}/anonymize", controllers.RequireRole(controllers.AnonymizeEmployee, controllers.RoleAdmin)).Methods("POST")
+
+	// Org chart
+	orgChart := export.HandleFunc("/org-chart.dot", controllers.GetOrgChartDOT).Methods("GET")
+	produces[orgChart] = []string{"text/vnd.graphviz"}
+
+	// Department routes
+	api.HandleFunc("/departments/rename", controllers.RequireRole(controllers.RenameDepartment, controllers.RoleAdmin)).Methods("POST")
+
+	// Live feed of employee changes over WebSocket
+	webSocket := feature("websocket", router, disabled).
+		Handle("/ws", controllers.RequireDatabase(http.HandlerFunc(controllers.ServeWebSocket))).Methods("GET")
+
+	// Health checks; /health/live never touches the database
+	health := ops.HandleFunc("/health", controllers.Health).Methods("GET", "HEAD")
+	liveness := ops.HandleFunc("/health/live", controllers.Liveness).Methods("GET", "HEAD")
+
+	// Metrics (expvar counters such as employee_cache_hit_ratio)
+	metrics := feature("metrics", ops, disabledOps).Handle("/metrics", expvar.Handler()).Methods("GET")
+
+	// Admin routes
+	admin.HandleFunc("/admin/reindex", controllers.RequireRole(controllers.Reindex, controllers.RoleAdmin)).Methods("POST")
+	admin.HandleFunc("/admin/normalize-phones", controllers.RequireRole(controllers.NormalizePhones, controllers.RoleAdmin)).Methods("POST")
+	admin.HandleFunc("/admin/snapshot", controllers.RequireRole(controllers.GetSnapshot, controllers.RoleAdmin)).Methods("GET")
+	admin.HandleFunc("/admin/restore", controllers.RequireRole(controllers.RestoreSnapshot, controllers.RoleAdmin)).Methods("POST")
+	admin.HandleFunc("/admin/maintenance", controllers.RequireRole(controllers.GetMaintenanceMode, controllers.RoleAdmin)).Methods("GET")
+	setMaintenance := admin.HandleFunc("/admin/maintenance", controllers.RequireRole(controllers.SetMaintenanceMode, controllers.RoleAdmin)).Methods("PUT")
 
-	return handlers.CORS(
+	// Routes that stay writable during maintenance.
+	maintenanceExempt := map[*mux.Route]bool{setMaintenance: true}
+
+	// {id} path variables are parsed once, answering malformed ids with 400.
+	api.Use(controllers.EmployeeIDParam)
+
+	// API routes need the database; /health and /metrics answer without it.
+	api.Use(controllers.RequireDatabase)
+
+	// Fault injection for client testing; inert outside non-production APP_ENVs.
+	api.Use(controllers.Chaos())
+
+	// Routes that don't count towards MAX_IN_FLIGHT_REQUESTS: probes must
+	// answer under load, and streams hold their request open indefinitely.
+	concurrencyExempt := map[*mux.Route]bool{health: true, liveness: true, metrics: true, eventStream: true, webSocket: true}
+
+	router.Use(
+		controllers.RequestLogger,
+		controllers.LimitConcurrency(concurrencyExempt),
+		controllers.Authenticate,
+		controllers.MaintenanceMode(maintenanceExempt),
+		controllers.NegotiateContent(produces),
+		controllers.PrettyJSON,
+		controllers.FieldAliases,
+	)
+
+	cors := handlers.CORS(
 		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
+		handlers.AllowedMethods(append(registeredMethods(router), http.MethodOptions)),
+		handlers.AllowedHeaders([]string{"Content-Type", "Authorization", "If-None-Match", "If-Match", "Last-Event-ID", "X-Field-Aliases"}),
+		handlers.ExposedHeaders([]string{"ETag", "X-Total-Count", "X-Result-Truncated", "X-Next-Cursor", "Retry-After"}),
 	)(router)
+
+	return ignoreTrailingSlash(hideDisabled(disabled, handleOptions(router, cors)))
+}
+
+// hideDisabled answers 404 for requests matching a route of disabled, the
+// routes of disabled features, before they can fall through to a route such
+// as /api/employees/{id} that would otherwise match their path.
+func hideDisabled(disabled *mux.Router, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var match mux.RouteMatch
+		if disabled.Match(r, &match) && match.MatchErr == nil {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ignoreTrailingSlash serves /api/employees/ exactly like /api/employees by
+// dropping the trailing slash before routing. Rewriting the path instead of
+// redirecting, as mux's StrictSlash does, keeps the method and body of POST,
+// PUT and PATCH requests, which many clients lose when following a 301.
+func ignoreTrailingSlash(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.URL.Path) > 1 && strings.HasSuffix(r.URL.Path, "/") {
+			r.URL.Path = strings.TrimRight(r.URL.Path, "/")
+			if r.URL.Path == "" {
+				r.URL.Path = "/"
+			}
+			r.URL.RawPath = strings.TrimSuffix(r.URL.RawPath, "/")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleOptions answers OPTIONS requests with the methods actually registered for
+// the requested path, instead of the global CORS list. Unknown paths get 404,
+// preflights for an unsupported method get 405, and valid preflights are handed
+// to the CORS middleware with Access-Control-Allow-Methods already narrowed.
+func handleOptions(router *mux.Router, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		methods := allowedMethods(router, r)
+		if len(methods) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		allow := strings.Join(append(methods, http.MethodOptions), ", ")
+		w.Header().Set("Allow", allow)
+
+		requested := r.Header.Get("Access-Control-Request-Method")
+		if requested == "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if requested != http.MethodOptions && !contains(methods, requested) {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Methods", allow)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedMethods returns the methods of every route whose path matches r.
+func allowedMethods(router *mux.Router, r *http.Request) []string {
+	var methods []string
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		routeMethods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range routeMethods {
+			if contains(methods, method) {
+				continue
+			}
+			probe := r.Clone(r.Context())
+			probe.Method = method
+			var match mux.RouteMatch
+			if route.Match(probe, &match) {
+				methods = append(methods, method)
+			}
+		}
+		return nil
+	})
+	sort.Strings(methods)
+	return methods
+}
+
+// registeredMethods returns the union of methods across all routes.
+func registeredMethods(router *mux.Router) []string {
+	var methods []string
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		routeMethods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range routeMethods {
+			if !contains(methods, method) {
+				methods = append(methods, method)
+			}
+		}
+		return nil
+	})
+	sort.Strings(methods)
+	return methods
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }